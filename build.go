@@ -0,0 +1,55 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"journal/internal/config"
+	"journal/internal/server"
+)
+
+// runBuild exports the whole site as static files for plain object storage:
+// journal build [-out dist] [-base-url URL]; the base URL may come from the
+// configuration instead but is required
+func runBuild(args []string) {
+	fs := flag.NewFlagSet("build", flag.ExitOnError)
+	settings := config.BindFlags(fs)
+	out := fs.String("out", "dist", "directory to write the site to")
+	drafts := fs.Bool("drafts", false, "include drafts and future-dated entries")
+	live := fs.Bool("live", false, "read templates, content and static files from disk instead of the binary")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: journal build [flags]")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if fs.NArg() != 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	cfg, err := settings.Load()
+	if err != nil {
+		log.Fatalf("Error loading configuration: %v", err)
+	}
+	if cfg.BaseURL == "" {
+		log.Fatal("No base URL: set base_url in the configuration, JOURNAL_BASE_URL or -base-url")
+	}
+
+	opts := server.Options{Drafts: *drafts}
+	if !*live {
+		opts.Files = files
+	}
+	s, err := server.New(cfg, opts)
+	if err != nil {
+		log.Fatalf("Error initializing server: %v", err)
+	}
+
+	stats, err := s.Export(*out)
+	if err != nil {
+		log.Fatalf("Error building site: %v", err)
+	}
+	fmt.Fprintf(os.Stderr, "Built %s: %d written, %d unchanged, %d removed\n",
+		*out, stats.Written, stats.Unchanged, stats.Removed)
+}
@@ -0,0 +1,12 @@
+package main
+
+import "embed"
+
+// files is the site as of the build, at the default paths in config.Dirs,
+// so the binary serves it from any directory; -live reads the configured
+// directories on disk instead
+//
+//go:embed internal/templates static
+//go:embed internal/content/articles internal/content/fragments internal/content/shelf
+//go:embed internal/content/pixels internal/content/about
+var files embed.FS
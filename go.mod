@@ -3,8 +3,10 @@ module journal
 go 1.25.3
 
 require (
+	github.com/BurntSushi/toml v1.6.0
 	github.com/yuin/goldmark v1.7.13
 	github.com/yuin/goldmark-highlighting/v2 v2.0.0-20230729083705-37449abec8cc
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
@@ -0,0 +1,364 @@
+// Package config loads the site configuration from journal.toml or
+// journal.yaml, JOURNAL_* environment variables and command-line flags,
+// each overriding the one before.
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// Sections lists every content section in menu order
+var Sections = []string{"articles", "fragments", "shelf", "pixels", "about"}
+
+// DefaultFiles are looked for in the working directory when no file is given
+var DefaultFiles = []string{"journal.toml", "journal.yaml", "journal.yml"}
+
+// Config is everything that differs between one journal and another
+type Config struct {
+	// Listen is the address the server binds: host:port for TCP or
+	// unix:/path/to.sock for a Unix socket. It is ignored when systemd
+	// passes the server a socket.
+	Listen string `toml:"listen" yaml:"listen"`
+
+	HTTP HTTP `toml:"http" yaml:"http"`
+
+	// BaseURL is the public site URL used for absolute links in feeds and
+	// the sitemap, which are only served when it is set
+	BaseURL string `toml:"base_url" yaml:"base_url"`
+
+	// PageSize is how many entries the articles, fragments and pixels
+	// listings show per page
+	PageSize int `toml:"page_size" yaml:"page_size"`
+
+	Site Site `toml:"site" yaml:"site"`
+	Dirs Dirs `toml:"dirs" yaml:"dirs"`
+
+	// Sections lists the enabled content sections; the others are neither
+	// loaded nor routed
+	Sections []string `toml:"sections" yaml:"sections"`
+
+	// Shelf lists the shelf categories in the order the shelf shows them
+	Shelf []ShelfCategory `toml:"shelf" yaml:"shelf"`
+
+	// Menu is the site navigation; when empty it links every enabled
+	// section followed by search
+	Menu []MenuItem `toml:"menu" yaml:"menu"`
+}
+
+// HTTP bounds what the server accepts from clients and how long it waits
+// on them; a zero timeout means none
+type HTTP struct {
+	ReadHeaderTimeout time.Duration `toml:"read_header_timeout" yaml:"read_header_timeout"`
+	ReadTimeout       time.Duration `toml:"read_timeout" yaml:"read_timeout"`
+	WriteTimeout      time.Duration `toml:"write_timeout" yaml:"write_timeout"`
+	IdleTimeout       time.Duration `toml:"idle_timeout" yaml:"idle_timeout"`
+	MaxHeaderBytes    int           `toml:"max_header_bytes" yaml:"max_header_bytes"`
+
+	// ShutdownTimeout is how long open requests may take to finish after
+	// SIGINT or SIGTERM before their connections are closed
+	ShutdownTimeout time.Duration `toml:"shutdown_timeout" yaml:"shutdown_timeout"`
+}
+
+// Site names the journal and its author and says what it holds
+type Site struct {
+	Title       string `toml:"title" yaml:"title"`
+	Author      string `toml:"author" yaml:"author"`
+	Description string `toml:"description" yaml:"description"`
+}
+
+// Dirs locates the content, templates and static files
+type Dirs struct {
+	Content   string `toml:"content" yaml:"content"`
+	Templates string `toml:"templates" yaml:"templates"`
+	Static    string `toml:"static" yaml:"static"`
+}
+
+// ShelfCategory is a shelf subdirectory and the heading it is shown under
+type ShelfCategory struct {
+	Key   string `toml:"key" yaml:"key"`
+	Title string `toml:"title" yaml:"title"`
+}
+
+// MenuItem is one link in the site navigation
+type MenuItem struct {
+	Title string `toml:"title" yaml:"title"`
+	URL   string `toml:"url" yaml:"url"`
+}
+
+// Default returns the configuration used when nothing overrides it
+func Default() Config {
+	return Config{
+		Listen: ":8080",
+		HTTP: HTTP{
+			ReadHeaderTimeout: 5 * time.Second,
+			ReadTimeout:       15 * time.Second,
+			WriteTimeout:      30 * time.Second,
+			IdleTimeout:       2 * time.Minute,
+			MaxHeaderBytes:    64 << 10,
+			ShutdownTimeout:   15 * time.Second,
+		},
+		PageSize: 20,
+		Site:     Site{Title: "Vamsi", Author: "Vamsi", Description: "Long-form writing and short notes"},
+		Dirs: Dirs{
+			Content:   "internal/content",
+			Templates: "internal/templates",
+			Static:    "static",
+		},
+		Sections: slices.Clone(Sections),
+		Shelf: []ShelfCategory{
+			{Key: "books", Title: "Bookshelf"},
+			{Key: "papers", Title: "Papers"},
+			{Key: "misc", Title: "Miscellany"},
+		},
+	}
+}
+
+// Enabled reports whether section is served
+func (c Config) Enabled(section string) bool {
+	return slices.Contains(c.Sections, section)
+}
+
+// DefaultShelfCategory is the category of shelf items that name none: the
+// first configured, or empty when the shelf has no categories
+func (c Config) DefaultShelfCategory() string {
+	if len(c.Shelf) == 0 {
+		return ""
+	}
+	return c.Shelf[0].Key
+}
+
+// ShelfKeys returns the shelf category keys in order
+func (c Config) ShelfKeys() []string {
+	keys := make([]string, len(c.Shelf))
+	for i, category := range c.Shelf {
+		keys[i] = category.Key
+	}
+	return keys
+}
+
+// Load reads file over the defaults, or the first of DefaultFiles that
+// exists when file is empty, then applies the environment. A .toml file is
+// decoded as TOML and anything else as YAML.
+func Load(file string) (Config, error) {
+	cfg, err := load(file)
+	if err != nil {
+		return Config{}, err
+	}
+	return cfg, cfg.finish()
+}
+
+// load is Load without finish, so flags can still override the result
+func load(file string) (Config, error) {
+	cfg := Default()
+
+	if file == "" {
+		for _, name := range DefaultFiles {
+			if _, err := os.Stat(name); err == nil {
+				file = name
+				break
+			}
+		}
+	}
+	if file != "" {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return Config{}, err
+		}
+		if filepath.Ext(file) == ".toml" {
+			_, err = toml.Decode(string(data), &cfg)
+		} else {
+			err = yaml.Unmarshal(data, &cfg)
+		}
+		if err != nil {
+			return Config{}, fmt.Errorf("%s: %w", file, err)
+		}
+	}
+
+	if err := cfg.applyEnv(os.LookupEnv); err != nil {
+		return Config{}, err
+	}
+	return cfg, nil
+}
+
+// setters maps each overridable setting to a parser that stores it; the
+// environment variable is JOURNAL_ followed by the name upper-cased with
+// hyphens as underscores, and the flag is the name itself
+func (c *Config) setters() map[string]func(string) error {
+	str := func(dst *string) func(string) error {
+		return func(v string) error { *dst = v; return nil }
+	}
+	integer := func(dst *int) func(string) error {
+		return func(v string) error {
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				return fmt.Errorf("%q is not a number", v)
+			}
+			*dst = n
+			return nil
+		}
+	}
+	duration := func(dst *time.Duration) func(string) error {
+		return func(v string) error {
+			d, err := time.ParseDuration(v)
+			if err != nil {
+				return fmt.Errorf("%q is not a duration such as 30s", v)
+			}
+			*dst = d
+			return nil
+		}
+	}
+	return map[string]func(string) error{
+		"listen":              str(&c.Listen),
+		"read-header-timeout": duration(&c.HTTP.ReadHeaderTimeout),
+		"read-timeout":        duration(&c.HTTP.ReadTimeout),
+		"write-timeout":       duration(&c.HTTP.WriteTimeout),
+		"idle-timeout":        duration(&c.HTTP.IdleTimeout),
+		"max-header-bytes":    integer(&c.HTTP.MaxHeaderBytes),
+		"shutdown-timeout":    duration(&c.HTTP.ShutdownTimeout),
+		"base-url":            str(&c.BaseURL),
+		"site-title":          str(&c.Site.Title),
+		"site-author":         str(&c.Site.Author),
+		"site-description":    str(&c.Site.Description),
+		"content-dir":         str(&c.Dirs.Content),
+		"templates-dir":       str(&c.Dirs.Templates),
+		"static-dir":          str(&c.Dirs.Static),
+		"page-size":           integer(&c.PageSize),
+		"sections": func(v string) error {
+			c.Sections = nil
+			for _, section := range strings.Split(v, ",") {
+				if section = strings.TrimSpace(section); section != "" {
+					c.Sections = append(c.Sections, section)
+				}
+			}
+			return nil
+		},
+	}
+}
+
+// flagUsage documents each setter for -help
+var flagUsage = map[string]string{
+	"listen":              "address to listen on: host:port or unix:/path/to.sock",
+	"read-header-timeout": "how long a client may take to send request headers",
+	"read-timeout":        "how long a client may take to send a whole request",
+	"write-timeout":       "how long writing a response may take",
+	"idle-timeout":        "how long a keep-alive connection may wait for its next request",
+	"max-header-bytes":    "largest request header accepted, in bytes",
+	"shutdown-timeout":    "how long open requests may take to finish on SIGINT or SIGTERM",
+	"base-url":            "public site URL for absolute links; feeds and the sitemap need it",
+	"site-title":          "site title",
+	"site-author":         "site author",
+	"site-description":    "what the site holds, used by the site-wide feeds",
+	"content-dir":         "directory holding one subdirectory per section",
+	"templates-dir":       "directory holding the page templates",
+	"static-dir":          "directory served under /static/",
+	"page-size":           "entries per page on the articles, fragments and pixels listings",
+	"sections":            "comma-separated sections to serve: " + strings.Join(Sections, ","),
+}
+
+func envName(setting string) string {
+	return "JOURNAL_" + strings.ToUpper(strings.ReplaceAll(setting, "-", "_"))
+}
+
+func (c *Config) applyEnv(lookup func(string) (string, bool)) error {
+	for setting, set := range c.setters() {
+		if v, ok := lookup(envName(setting)); ok {
+			if err := set(v); err != nil {
+				return fmt.Errorf("%s: %w", envName(setting), err)
+			}
+		}
+	}
+	return nil
+}
+
+// finish fills in what depends on other settings and rejects what the
+// server could not run with
+func (c *Config) finish() error {
+	// An empty list would read as "all sections" to the content store
+	if len(c.Sections) == 0 {
+		return fmt.Errorf("no sections enabled: want some of %s", strings.Join(Sections, ", "))
+	}
+	for _, section := range c.Sections {
+		if !slices.Contains(Sections, section) {
+			return fmt.Errorf("unknown section %q: want %s", section, strings.Join(Sections, ", "))
+		}
+	}
+	if c.PageSize <= 0 {
+		return errors.New("page size must be positive")
+	}
+	for _, t := range []time.Duration{c.HTTP.ReadHeaderTimeout, c.HTTP.ReadTimeout,
+		c.HTTP.WriteTimeout, c.HTTP.IdleTimeout, c.HTTP.ShutdownTimeout} {
+		if t < 0 {
+			return errors.New("timeouts cannot be negative")
+		}
+	}
+	if c.HTTP.MaxHeaderBytes < 0 {
+		return errors.New("max header bytes cannot be negative")
+	}
+	if len(c.Shelf) == 0 && c.Enabled("shelf") {
+		return errors.New("the shelf needs at least one category")
+	}
+
+	if len(c.Menu) == 0 {
+		for _, section := range Sections {
+			if c.Enabled(section) {
+				c.Menu = append(c.Menu, MenuItem{Title: strings.ToUpper(section), URL: "/" + section})
+			}
+		}
+		c.Menu = append(c.Menu, MenuItem{Title: "SEARCH", URL: "/search"})
+	}
+	return nil
+}
+
+// Flags binds the -config flag and one flag per setting to a FlagSet, so
+// a command can parse its arguments before the configuration is loaded
+type Flags struct {
+	fs     *flag.FlagSet
+	file   *string
+	values map[string]*string
+}
+
+// BindFlags registers the configuration flags on fs
+func BindFlags(fs *flag.FlagSet) *Flags {
+	f := &Flags{
+		fs:     fs,
+		file:   fs.String("config", "", "configuration file (default: "+strings.Join(DefaultFiles, ", ")+" if present)"),
+		values: make(map[string]*string),
+	}
+	for setting := range (&Config{}).setters() {
+		f.values[setting] = fs.String(setting, "", flagUsage[setting]+" (env "+envName(setting)+")")
+	}
+	return f
+}
+
+// Load loads the configuration named by -config and applies the flags
+// that were set on the command line; call it after fs.Parse
+func (f *Flags) Load() (Config, error) {
+	cfg, err := load(*f.file)
+	if err != nil {
+		return Config{}, err
+	}
+
+	setters := cfg.setters()
+	f.fs.Visit(func(fl *flag.Flag) {
+		if set, ok := setters[fl.Name]; ok && err == nil {
+			if e := set(*f.values[fl.Name]); e != nil {
+				err = fmt.Errorf("-%s: %w", fl.Name, e)
+			}
+		}
+	})
+	if err != nil {
+		return Config{}, err
+	}
+	return cfg, cfg.finish()
+}
@@ -2,7 +2,7 @@ package content
 
 import (
     "html/template"
-    "io/ioutil"
+    "io/fs"
     "path/filepath"
     "strings"
 
@@ -10,28 +10,36 @@ import (
     "journal/internal/render"
 )
 
-func LoadAbout() (models.About, error) {
-    files, err := filepath.Glob("internal/content/about/about.md")
+func LoadAbout(opts Options) (models.About, error) {
+    src := opts.source()
+    files, err := fs.Glob(src.fsys, aboutGlob)
     if err != nil || len(files) == 0 {
         return models.About{}, err
     }
 
-    data, err := ioutil.ReadFile(files[0])
+    data, err := fs.ReadFile(src.fsys, files[0])
     if err != nil {
         return models.About{}, err
     }
 
-    md := string(data)
-    md, frontmatter := parseFrontmatter(md)
+    var meta aboutMeta
+    md, err := parseFrontmatter(src.path(files[0]), string(data), &meta)
+    if err != nil {
+        return models.About{}, err
+    }
 
-    html, _, err := render.MarkdownToHTML(md)
+    doc := render.Parse(md)
+    html, _, err := doc.HTML()
     if err != nil {
         return models.About{}, err
     }
 
     slug := strings.TrimSuffix(filepath.Base(files[0]), ".md")
-    image := frontmatter["image"]
-    date := extractDate(files[0], frontmatter)
+    image := meta.Image
+    date, err := extractDate(src, files[0], meta.Date)
+    if err != nil {
+        return models.About{}, err
+    }
 
     return models.About{
         Slug:  slug,
@@ -0,0 +1,61 @@
+package content
+
+import (
+	"time"
+
+	"journal/internal/models"
+)
+
+// Archive returns every year with entries across sections, newest first
+func (s *Store) Archive() []models.ArchiveYear {
+	s.refresh()
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.archive
+}
+
+// indexArchive groups every live entry by year and month; called from index
+func (s *Store) indexArchive() {
+	var entries []models.ArchiveEntry
+	for _, a := range s.liveArticles {
+		entries = append(entries, models.ArchiveEntry{
+			Section: "articles", Slug: a.Slug, Title: a.Title,
+			URL: "/articles/" + a.Slug, Date: a.Date,
+		})
+	}
+	for _, f := range s.liveFragments {
+		entries = append(entries, models.ArchiveEntry{
+			Section: "fragments", Slug: f.Slug, Title: f.Title,
+			URL: "/fragments/" + f.Slug, Date: f.Date,
+		})
+	}
+	for _, item := range s.liveShelf {
+		entries = append(entries, models.ArchiveEntry{
+			Section: "shelf", Slug: item.Slug, Title: item.Title,
+			URL: "/shelf/" + item.Slug, Date: item.Date,
+		})
+	}
+	for _, p := range s.livePixels {
+		entries = append(entries, models.ArchiveEntry{
+			Section: "pixels", Slug: p.Slug, Title: p.Title,
+			URL: "/pixels/" + p.Slug, Date: p.Date,
+		})
+	}
+	dateOf := func(e models.ArchiveEntry) time.Time { return e.Date }
+	sortNewestFirst(entries, dateOf)
+
+	byYear, years := groupByYear(entries, dateOf)
+	s.archive = make([]models.ArchiveYear, 0, len(years))
+	for _, year := range years {
+		y := models.ArchiveYear{Year: year, Count: len(byYear[year])}
+		for _, e := range byYear[year] {
+			month := e.Date.Month()
+			if n := len(y.Months); n == 0 || y.Months[n-1].Month != month {
+				y.Months = append(y.Months, models.ArchiveMonth{Year: year, Month: month})
+			}
+			last := &y.Months[len(y.Months)-1]
+			last.Entries = append(last.Entries, e)
+		}
+		s.archive = append(s.archive, y)
+	}
+}
@@ -1,11 +1,10 @@
 package content
 
 import (
+	"fmt"
 	"html/template"
-	"io/ioutil"
-	"os"
+	"io/fs"
 	"path/filepath"
-	"regexp"
 	"strings"
 	"time"
 
@@ -13,8 +12,9 @@ import (
 	"journal/internal/render"
 )
 
-func LoadArticles() ([]models.Article, error) {
-	files, err := filepath.Glob("internal/content/articles/*.md")
+func LoadArticles(opts Options) ([]models.Article, error) {
+	src := opts.source()
+	files, err := fs.Glob(src.fsys, articlesGlob)
 	if err != nil {
 		return nil, err
 	}
@@ -22,93 +22,75 @@ func LoadArticles() ([]models.Article, error) {
 	var articles []models.Article
 
 	for _, file := range files {
-		data, err := ioutil.ReadFile(file)
+		article, err := loadArticle(src, file)
 		if err != nil {
 			return nil, err
 		}
-
-		md := string(data)
-		md, frontmatter := parseFrontmatter(md)
-
-		html, toc, err := render.MarkdownToHTML(md)
-		if err != nil {
-			return nil, err
-		}
-
-		slug := strings.TrimSuffix(filepath.Base(file), ".md")
-		title := extractTitle(md, frontmatter)
-		summary := extractSummary(md, frontmatter)
-		date := extractDate(file, frontmatter)
-		tags := extractTags(frontmatter)
-		image := fetchImageUrl(frontmatter)
-
-		articles = append(articles, models.Article{
-			Slug:            slug,
-			Title:           title,
-			Summary:         summary,
-			Date:            date,
-			HTML:            template.HTML(html),
-			TableOfContents: template.HTML(toc),
-			Tags:            tags,
-			Image:           image,
-		})
+		articles = append(articles, article)
 	}
 	return articles, nil
 }
 
-// parseFrontmatter extracts YAML frontmatter from markdown
-// Returns markdown without frontmatter and a map of frontmatter fields
-func parseFrontmatter(md string) (string, map[string]string) {
-	frontmatter := make(map[string]string)
-
-	// Check for frontmatter delimiter (---)
-	if !strings.HasPrefix(md, "---") {
-		return md, frontmatter
+// loadArticle reads and renders a single article file
+func loadArticle(src source, file string) (models.Article, error) {
+	data, err := fs.ReadFile(src.fsys, file)
+	if err != nil {
+		return models.Article{}, err
 	}
 
-	// Find the end of frontmatter (second ---)
-	firstDelim := strings.Index(md, "---")
-	if firstDelim == -1 {
-		return md, frontmatter
+	var meta articleMeta
+	md, err := parseFrontmatter(src.path(file), string(data), &meta)
+	if err != nil {
+		return models.Article{}, err
 	}
 
-	// Find second delimiter
-	remaining := md[firstDelim+3:]
-	// Skip whitespace/newlines
-	remaining = strings.TrimLeft(remaining, " \n\r\t")
-	secondDelim := strings.Index(remaining, "---")
-	if secondDelim == -1 {
-		return md, frontmatter
+	doc := render.Parse(md)
+	html, toc, err := doc.HTML()
+	if err != nil {
+		return models.Article{}, err
 	}
 
-	// Extract frontmatter content
-	frontmatterContent := remaining[:secondDelim]
-	markdownContent := remaining[secondDelim+3:]
-
-	// Parse frontmatter lines
-	lines := strings.Split(frontmatterContent, "\n")
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if line == "" {
-			continue
-		}
-		parts := strings.SplitN(line, ":", 2)
-		if len(parts) == 2 {
-			key := strings.TrimSpace(parts[0])
-			value := strings.TrimSpace(parts[1])
-			// Remove quotes if present
-			value = strings.Trim(value, `"'`)
-			frontmatter[key] = value
-		}
+	slug := strings.TrimSuffix(filepath.Base(file), ".md")
+	title := extractTitle(md, meta.Title)
+	summary := extractSummary(doc, meta.Summary)
+	excerpt, err := extractExcerpt(doc)
+	if err != nil {
+		return models.Article{}, err
 	}
-
-	// Return markdown without frontmatter
-	return strings.TrimLeft(markdownContent, " \n\r\t"), frontmatter
+	date, err := extractDate(src, file, meta.Date)
+	if err != nil {
+		return models.Article{}, err
+	}
+	updated := extractUpdated(src, file, meta.Updated, date)
+	publishAt := extractPublishAt(meta.PublishAt, date)
+	stats := doc.Stats()
+	tags := []string(meta.Tags)
+	image := meta.Image
+
+	return models.Article{
+		Slug:            slug,
+		Title:           title,
+		Summary:         summary,
+		Excerpt:         excerpt,
+		Date:            date,
+		Updated:         updated,
+		HTML:            template.HTML(html),
+		PlainText:       stats.Plain,
+		WordCount:       stats.Words,
+		ReadingTime:     stats.ReadingTime(),
+		TableOfContents: template.HTML(toc),
+		Tags:            tags,
+		Image:           image,
+		Series:          strings.TrimSpace(meta.Series),
+		SeriesOrder:     meta.SeriesOrder,
+		Draft:           meta.Draft,
+		PublishAt:       publishAt,
+	}, nil
 }
 
-func extractTitle(md string, frontmatter map[string]string) string {
+func extractTitle(md string, title string) string {
 	// Check frontmatter first
-	if title, ok := frontmatter["title"]; ok && title != "" {
+	if title != "" {
 		return title
 	}
 
@@ -122,73 +104,103 @@ func extractTitle(md string, frontmatter map[string]string) string {
 	return "Untitled"
 }
 
-func extractSummary(md string, frontmatter map[string]string) string {
-	if summary, ok := frontmatter["summary"]; ok && summary != "" {
-		return summary
+// summaryLength caps generated summaries, in runes
+const summaryLength = 220
+
+// extractSummary returns the frontmatter summary when set; otherwise the
+// text before <!--more--> or, failing that, the first paragraph, cut at a
+// word boundary to summaryLength runes
+func extractSummary(doc *render.Document, summary string) string {
+	if summary != "" {
+		return strings.TrimSpace(summary)
 	}
 
-	// Remove markdown headers and trim
-	paragraphs := strings.Split(md, "\n\n")
-	for _, p := range paragraphs {
-		trimmed := strings.TrimSpace(p)
-		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
-			continue
-		}
+	if excerpt, ok := doc.Excerpt(); ok {
+		return truncate(excerpt.Stats().Plain, summaryLength)
+	}
+	return truncate(doc.Stats().Lead, summaryLength)
+}
 
-		// Remove markdown formatting for summary
-		plain := trimmed
-		plain = strings.ReplaceAll(plain, "**", "")
-		plain = strings.ReplaceAll(plain, "*", "")
-		plain = strings.ReplaceAll(plain, "_", "")
+// extractExcerpt renders the markdown before <!--more--> for listing pages;
+// it is empty when there is no marker
+func extractExcerpt(doc *render.Document) (template.HTML, error) {
+	excerpt, ok := doc.Excerpt()
+	if !ok {
+		return "", nil
+	}
+	html, _, err := excerpt.HTML()
+	return template.HTML(html), err
+}
 
-		// Remove inline links [text](url)
-		linkRegex := regexp.MustCompile(`\[(.*?)\]\((.*?)\)`)
-		plain = linkRegex.ReplaceAllString(plain, "$1")
+// truncate collapses whitespace in s and shortens it to at most max runes,
+// cutting at the last word boundary and marking the cut with an ellipsis
+func truncate(s string, max int) string {
+	s = strings.Join(strings.Fields(s), " ")
+	runes := []rune(s)
+	if len(runes) <= max {
+		return s
+	}
 
-		if len(plain) > 220 {
-			return plain[:220] + "..."
+	cut := string(runes[:max])
+	if runes[max] != ' ' {
+		if i := strings.LastIndexByte(cut, ' '); i > 0 {
+			cut = cut[:i]
 		}
-		return plain
 	}
-
-	return ""
+	return strings.TrimRight(cut, " ,;:.-–—") + "…"
 }
 
-func extractDate(file string, frontmatter map[string]string) time.Time {
+// extractDate returns the frontmatter date, else when the file was first
+// committed, else its mtime. Files compiled into the binary have neither of
+// the fallbacks, and the current time would move the entry on every
+// restart, so they must set a date.
+func extractDate(src source, file string, date metaDate) (time.Time, error) {
 	// Check frontmatter first
-	if dateStr, ok := frontmatter["date"]; ok && dateStr != "" {
-		// Try common date formats
-		formats := []string{
-			"2006-01-02",
-			"2006-01-02T15:04:05Z07:00",
-			"2006-01-02 15:04:05",
-		}
-		for _, format := range formats {
-			if t, err := time.Parse(format, dateStr); err == nil {
-				return t
-			}
-		}
+	if !date.IsZero() {
+		return date.Time, nil
+	}
+	if src.dir == "" {
+		return time.Time{}, fmt.Errorf("%s: no date in frontmatter, which embedded content needs", src.path(file))
+	}
+
+	// Then the commit that added the file, which unlike its mtime survives
+	// a fresh checkout
+	if d, ok := src.history(file); ok {
+		return d.Created, nil
 	}
 
 	// Fallback to file modification time
-	if info, err := os.Stat(file); err == nil {
-		return info.ModTime()
+	if info, err := fs.Stat(src.fsys, file); err == nil {
+		return info.ModTime(), nil
 	}
 
 	// Last resort: current time
-	return time.Now()
+	return time.Now(), nil
 }
 
-func extractTags(frontmatter map[string]string) []string {
-    tags := []string{}
-    if tagStr, ok := frontmatter["tags"]; ok {
-        tagList := strings.Split(tagStr, ",")
-        for _, tag := range tagList {
-            tag = strings.TrimSpace(tag)
-            if tag != "" {
-                tags = append(tags, tag)
-            }
-        }
-    }
-    return tags
+// extractUpdated returns when an entry was last revised: the frontmatter
+// updated date if set, otherwise the last commit to change the file after
+// the one that added it. It is zero unless it falls on a later day than date.
+func extractUpdated(src source, file string, updated metaDate, date time.Time) time.Time {
+	t := updated.Time
+	if t.IsZero() {
+		if d, ok := src.history(file); ok && d.Commits > 1 {
+			t = d.Modified
+		}
+	}
+
+	const day = "2006-01-02"
+	if t.IsZero() || t.In(date.Location()).Format(day) <= date.Format(day) {
+		return time.Time{}
+	}
+	return t
+}
+
+// extractPublishAt returns when an entry goes live: publishAt if set,
+// otherwise its date, so future-dated entries are scheduled automatically
+func extractPublishAt(publishAt metaDate, date time.Time) time.Time {
+	if !publishAt.IsZero() {
+		return publishAt.Time
+	}
+	return date
 }
@@ -2,7 +2,7 @@ package content
 
 import (
 	"html/template"
-	"io/ioutil"
+	"io/fs"
 	"path/filepath"
 	"strings"
 
@@ -10,8 +10,9 @@ import (
 	"journal/internal/render"
 )
 
-func LoadFragments() ([]models.Fragment, error) {
-	files, err := filepath.Glob("internal/content/fragments/*.md")
+func LoadFragments(opts Options) ([]models.Fragment, error) {
+	src := opts.source()
+	files, err := fs.Glob(src.fsys, fragmentsGlob)
 	if err != nil {
 		return nil, err
 	}
@@ -19,36 +20,63 @@ func LoadFragments() ([]models.Fragment, error) {
 	var fragments []models.Fragment
 
 	for _, file := range files {
-		data, err := ioutil.ReadFile(file)
+		fragment, err := loadFragment(src, file)
 		if err != nil {
 			return nil, err
 		}
+		fragments = append(fragments, fragment)
+	}
 
-		md := string(data)
-		md, frontmatter := parseFrontmatter(md)
+	return fragments, nil
+}
 
-		html, _, err := render.MarkdownToHTML(md)
-		if err != nil {
-			return nil, err
-		}
+// loadFragment reads and renders a single fragment file
+func loadFragment(src source, file string) (models.Fragment, error) {
+	data, err := fs.ReadFile(src.fsys, file)
+	if err != nil {
+		return models.Fragment{}, err
+	}
+
+	var meta fragmentMeta
+	md, err := parseFrontmatter(src.path(file), string(data), &meta)
+	if err != nil {
+		return models.Fragment{}, err
+	}
 
-		slug := strings.TrimSuffix(filepath.Base(file), ".md")
-		title := extractTitle(md, frontmatter)
-		date := extractDate(file, frontmatter)
-		image := fetchImageUrl(frontmatter)
-
-		fragments = append(fragments, models.Fragment{
-			Slug:  slug,
-			Title: title,
-		    Image: image,
-			Date:  date,
-			HTML:  template.HTML(html),
-		})
+	doc := render.Parse(md)
+	html, _, err := doc.HTML()
+	if err != nil {
+		return models.Fragment{}, err
 	}
 
-	return fragments, nil
-}
+	slug := strings.TrimSuffix(filepath.Base(file), ".md")
+	title := extractTitle(md, meta.Title)
+	excerpt, err := extractExcerpt(doc)
+	if err != nil {
+		return models.Fragment{}, err
+	}
+	date, err := extractDate(src, file, meta.Date)
+	if err != nil {
+		return models.Fragment{}, err
+	}
+	updated := extractUpdated(src, file, meta.Updated, date)
+	publishAt := extractPublishAt(meta.PublishAt, date)
+	stats := doc.Stats()
+	image := meta.Image
 
-func fetchImageUrl(frontmatter map[string]string) string {
-    return frontmatter["image"]
+	return models.Fragment{
+		Slug:        slug,
+		Title:       title,
+		Image:       image,
+		Date:        date,
+		Updated:     updated,
+		HTML:        template.HTML(html),
+		Excerpt:     excerpt,
+		PlainText:   stats.Plain,
+		WordCount:   stats.Words,
+		ReadingTime: stats.ReadingTime(),
+		Tags:        []string(meta.Tags),
+		Draft:       meta.Draft,
+		PublishAt:   publishAt,
+	}, nil
 }
@@ -0,0 +1,287 @@
+package content
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// commonMeta holds the frontmatter fields shared by every section.
+// Each field is tagged for all three header formats so YAML, TOML and JSON
+// headers decode into the same typed metadata.
+type commonMeta struct {
+	Title   string   `yaml:"title" toml:"title" json:"title"`
+	Date    metaDate `yaml:"date" toml:"date" json:"date"`
+	Image   string   `yaml:"image" toml:"image" json:"image"`
+	Tags    tagList  `yaml:"tags" toml:"tags" json:"tags"`
+	Summary string   `yaml:"summary" toml:"summary" json:"summary"`
+
+	// Updated marks a revision; without it the last git commit is used
+	Updated metaDate `yaml:"updated" toml:"updated" json:"updated"`
+
+	// Draft hides the entry; PublishAt (or a future Date) schedules it
+	Draft     bool     `yaml:"draft" toml:"draft" json:"draft"`
+	PublishAt metaDate `yaml:"publishAt" toml:"publishAt" json:"publishAt"`
+}
+
+type articleMeta struct {
+	commonMeta `yaml:",inline"`
+
+	// Series groups multi-part articles; SeriesOrder places this one in it
+	Series      string `yaml:"series" toml:"series" json:"series"`
+	SeriesOrder int    `yaml:"seriesOrder" toml:"seriesOrder" json:"seriesOrder"`
+}
+
+type fragmentMeta struct {
+	commonMeta `yaml:",inline"`
+}
+
+type shelfMeta struct {
+	commonMeta `yaml:",inline"`
+	SubTitle   string `yaml:"sub-title" toml:"sub-title" json:"sub-title"`
+	Author     string `yaml:"author" toml:"author" json:"author"`
+	Category   string `yaml:"category" toml:"category" json:"category"`
+}
+
+type pixelMeta struct {
+	commonMeta `yaml:",inline"`
+}
+
+type aboutMeta struct {
+	commonMeta `yaml:",inline"`
+}
+
+// metaDate accepts the date layouts we write by hand in frontmatter
+type metaDate struct {
+	time.Time
+}
+
+var dateFormats = []string{
+	"2006-01-02",
+	"2006-01-02T15:04:05Z07:00",
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+}
+
+func (d *metaDate) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind != yaml.ScalarNode {
+		return fmt.Errorf("line %d: date must be a scalar", node.Line)
+	}
+	if node.Tag == "!!null" || node.Value == "" {
+		return nil
+	}
+	t, err := parseDate(node.Value)
+	if err != nil {
+		return fmt.Errorf("line %d: %w", node.Line, err)
+	}
+	d.Time = t
+	return nil
+}
+
+func (d *metaDate) UnmarshalTOML(v any) error {
+	switch value := v.(type) {
+	case time.Time:
+		// Local dates and datetimes carry a "*-local" zone; read them as
+		// wall-clock UTC like their YAML equivalents
+		if strings.HasSuffix(value.Location().String(), "-local") {
+			value = time.Date(value.Year(), value.Month(), value.Day(),
+				value.Hour(), value.Minute(), value.Second(), value.Nanosecond(), time.UTC)
+		}
+		d.Time = value
+		return nil
+	case string:
+		if value == "" {
+			return nil
+		}
+		t, err := parseDate(value)
+		if err != nil {
+			return err
+		}
+		d.Time = t
+		return nil
+	}
+	return fmt.Errorf("date must be a datetime or a string, got %T", v)
+}
+
+func (d *metaDate) UnmarshalJSON(data []byte) error {
+	var value *string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return fmt.Errorf("date must be a string")
+	}
+	if value == nil || *value == "" {
+		return nil
+	}
+	t, err := parseDate(*value)
+	if err != nil {
+		return err
+	}
+	d.Time = t
+	return nil
+}
+
+func parseDate(value string) (time.Time, error) {
+	for _, format := range dateFormats {
+		if t, err := time.Parse(format, value); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unparseable date %q", value)
+}
+
+// tagList accepts both a YAML list and the legacy comma-separated string
+// Example: "tags: [a, b]", a block list, or "tags: a, b"
+type tagList []string
+
+func (t *tagList) UnmarshalYAML(node *yaml.Node) error {
+	var raw []string
+	switch node.Kind {
+	case yaml.SequenceNode:
+		if err := node.Decode(&raw); err != nil {
+			return err
+		}
+	case yaml.ScalarNode:
+		raw = strings.Split(node.Value, ",")
+	default:
+		return fmt.Errorf("line %d: tags must be a list or a comma-separated string", node.Line)
+	}
+
+	t.set(raw)
+	return nil
+}
+
+func (t *tagList) UnmarshalTOML(v any) error {
+	switch value := v.(type) {
+	case string:
+		t.set(strings.Split(value, ","))
+		return nil
+	case []any:
+		raw := make([]string, 0, len(value))
+		for _, item := range value {
+			tag, ok := item.(string)
+			if !ok {
+				return fmt.Errorf("tags must be strings, got %T", item)
+			}
+			raw = append(raw, tag)
+		}
+		t.set(raw)
+		return nil
+	}
+	return fmt.Errorf("tags must be an array or a comma-separated string, got %T", v)
+}
+
+func (t *tagList) UnmarshalJSON(data []byte) error {
+	var raw []string
+	if err := json.Unmarshal(data, &raw); err == nil {
+		t.set(raw)
+		return nil
+	}
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return fmt.Errorf("tags must be an array or a comma-separated string")
+	}
+	t.set(strings.Split(value, ","))
+	return nil
+}
+
+// set stores the trimmed, non-empty tags
+func (t *tagList) set(raw []string) {
+	tags := tagList{}
+	for _, tag := range raw {
+		tag = strings.TrimSpace(tag)
+		if tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	*t = tags
+}
+
+// parseFrontmatter splits the header off a markdown file and decodes it
+// into meta. The opening delimiter selects the decoder:
+//
+//	---  YAML, closed by a line that is exactly "---"
+//	+++  TOML, closed by a line that is exactly "+++"
+//	{    a JSON object at the very start of the file
+//
+// Errors carry the file's line numbers. Returns the markdown without frontmatter.
+func parseFrontmatter(file, md string, meta any) (string, error) {
+	md = strings.TrimPrefix(md, "\ufeff")
+
+	var body string
+	var err error
+	switch {
+	case opensWith(md, "---"):
+		var header string
+		if header, body, err = splitFrontmatter(md, "---"); err == nil {
+			// Pad with the opening delimiter's line so errors report file lines
+			err = yaml.Unmarshal([]byte("\n"+header), meta)
+		}
+	case opensWith(md, "+++"):
+		var header string
+		if header, body, err = splitFrontmatter(md, "+++"); err == nil {
+			_, err = toml.Decode("\n"+header, meta)
+		}
+	case strings.HasPrefix(md, "{"):
+		body, err = decodeJSONFrontmatter(md, meta)
+	default:
+		return md, nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("%s: frontmatter: %w", file, err)
+	}
+
+	return strings.TrimLeft(body, " \n\r\t"), nil
+}
+
+// opensWith reports whether the first line of md is exactly delim
+func opensWith(md, delim string) bool {
+	first, _, _ := strings.Cut(md, "\n")
+	return strings.TrimRight(first, " \t\r") == delim
+}
+
+// splitFrontmatter returns the header between the delim lines and the body after it
+func splitFrontmatter(md, delim string) (header, body string, err error) {
+	_, rest, found := strings.Cut(md, "\n")
+	if !found {
+		return "", "", fmt.Errorf("line 1: unterminated frontmatter, no closing %s", delim)
+	}
+
+	offset := 0
+	for _, line := range strings.SplitAfter(rest, "\n") {
+		if strings.TrimRight(line, " \t\r\n") == delim {
+			return rest[:offset], rest[offset+len(line):], nil
+		}
+		offset += len(line)
+	}
+
+	return "", "", fmt.Errorf("line 1: unterminated frontmatter, no closing %s", delim)
+}
+
+// decodeJSONFrontmatter decodes the leading JSON object and returns the rest of md
+func decodeJSONFrontmatter(md string, meta any) (string, error) {
+	dec := json.NewDecoder(strings.NewReader(md))
+	if err := dec.Decode(meta); err != nil {
+		var syntaxErr *json.SyntaxError
+		var typeErr *json.UnmarshalTypeError
+		switch {
+		case errors.As(err, &syntaxErr):
+			return "", fmt.Errorf("line %d: %w", lineAt(md, syntaxErr.Offset), err)
+		case errors.As(err, &typeErr):
+			return "", fmt.Errorf("line %d: %w", lineAt(md, typeErr.Offset), err)
+		}
+		return "", err
+	}
+	return md[dec.InputOffset():], nil
+}
+
+// lineAt returns the 1-based line number of a byte offset into s
+func lineAt(s string, offset int64) int {
+	if offset > int64(len(s)) {
+		offset = int64(len(s))
+	}
+	return strings.Count(s[:offset], "\n") + 1
+}
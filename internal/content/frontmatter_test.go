@@ -0,0 +1,71 @@
+package content
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseFrontmatter(t *testing.T) {
+	tests := []struct {
+		name  string
+		md    string
+		title string
+		tags  []string
+		body  string
+	}{
+		{"yaml", "---\ntitle: Hello\ntags: go, web\n---\n\nBody.", "Hello", []string{"go", "web"}, "Body."},
+		{"yaml crlf", "---\r\ntitle: Hello\r\n---\r\nBody.", "Hello", nil, "Body."},
+		{"yaml bom", "\ufeff---\ntitle: Hello\n---\nBody.", "Hello", nil, "Body."},
+		{"yaml empty", "---\n---\nBody.", "", nil, "Body."},
+		{"yaml dashes in body", "---\ntitle: Hello\n---\nOne\n---\nTwo", "Hello", nil, "One\n---\nTwo"},
+		{"toml", "+++\ntitle = \"Hello\"\ntags = [\"go\"]\n+++\nBody.", "Hello", []string{"go"}, "Body."},
+		{"json", "{\"title\": \"Hello\", \"tags\": [\"go\"]}\n\nBody.", "Hello", []string{"go"}, "Body."},
+		{"none", "Just text.\n---\n", "", nil, "Just text.\n---\n"},
+		{"delimiter not alone", "--- title\nBody.", "", nil, "--- title\nBody."},
+	}
+	for _, tt := range tests {
+		var meta commonMeta
+		body, err := parseFrontmatter("x.md", tt.md, &meta)
+		if err != nil {
+			t.Errorf("%s: parseFrontmatter: %v", tt.name, err)
+			continue
+		}
+		if meta.Title != tt.title {
+			t.Errorf("%s: title = %q, want %q", tt.name, meta.Title, tt.title)
+		}
+		if strings.Join(meta.Tags, ",") != strings.Join(tt.tags, ",") {
+			t.Errorf("%s: tags = %q, want %q", tt.name, meta.Tags, tt.tags)
+		}
+		if body != tt.body {
+			t.Errorf("%s: body = %q, want %q", tt.name, body, tt.body)
+		}
+	}
+}
+
+func TestParseFrontmatterErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		md   string
+		want string // substring of the error
+	}{
+		{"yaml unterminated", "---\ntitle: Hello\n", "x.md: frontmatter: line 1: unterminated frontmatter, no closing ---"},
+		{"yaml no newline", "---", "line 1: unterminated frontmatter"},
+		{"toml unterminated", "+++\ntitle = \"Hello\"\n", "line 1: unterminated frontmatter, no closing +++"},
+		{"yaml syntax", "---\ntitle: Hello\n  draft: true\n---\n", "line 3"},
+		{"yaml type", "---\ntitle: Hello\n\ndate: [1, 2]\n---\n", "line 4"},
+		{"toml syntax", "+++\ntitle = \"Hello\"\ntitle = \"Again\"\n+++\n", "line 3"},
+		{"json syntax", "{\n\"title\": \"Hello\",\n}\n", "line 3"},
+		{"json type", "{\n\"title\": \"Hello\",\n\"draft\": \"yes\"\n}\n", "line 3"},
+	}
+	for _, tt := range tests {
+		var meta commonMeta
+		_, err := parseFrontmatter("x.md", tt.md, &meta)
+		if err == nil {
+			t.Errorf("%s: parseFrontmatter succeeded, want an error containing %q", tt.name, tt.want)
+			continue
+		}
+		if !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: error = %q, want it to contain %q", tt.name, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package content
+
+import (
+	"errors"
+	"io/fs"
+	"log"
+	"path/filepath"
+	"sync"
+
+	"journal/internal/gitlog"
+)
+
+// history caches when each content file was first and last committed, so
+// dates survive a fresh checkout that resets every mtime. The whole content
+// directory is read on first use, in one walk for every file in every
+// section, and dropped whenever HEAD moves.
+var history struct {
+	sync.Mutex
+	opened bool
+	repo   *gitlog.Repo
+	head   string
+	dir    string                  // the content directory dates holds
+	dates  map[string]gitlog.Dates // by path relative to the repo root
+}
+
+// fileHistory returns the git dates of file in the content directory dir,
+// or false when dir is not in a git repository or file was never committed
+func fileHistory(dir, file string) (gitlog.Dates, bool) {
+	history.Lock()
+	defer history.Unlock()
+
+	if !history.opened {
+		history.opened = true
+		// The content directory may live in a repository of its own
+		repo, err := gitlog.Open(dir)
+		if err != nil {
+			if !errors.Is(err, gitlog.ErrNoRepo) {
+				log.Printf("content: reading git history: %v", err)
+			}
+			return gitlog.Dates{}, false
+		}
+		history.repo = repo
+	}
+	if history.repo == nil {
+		return gitlog.Dates{}, false
+	}
+
+	head, err := history.repo.Head()
+	if err != nil {
+		log.Printf("content: reading git history: %v", err)
+		return gitlog.Dates{}, false
+	}
+	root := history.repo.Root()
+	if history.dates == nil || head != history.head || dir != history.dir {
+		var paths []string
+		filepath.WalkDir(dir, func(file string, d fs.DirEntry, err error) error {
+			if err == nil && !d.IsDir() && filepath.Ext(file) == ".md" {
+				if path, ok := repoPath(root, file); ok {
+					paths = append(paths, path)
+				}
+			}
+			return nil
+		})
+		dates, err := history.repo.History(paths)
+		if err != nil {
+			log.Printf("content: reading git history: %v", err)
+			return gitlog.Dates{}, false
+		}
+		history.head, history.dir, history.dates = head, dir, dates
+	}
+
+	path, ok := repoPath(root, file)
+	if !ok {
+		return gitlog.Dates{}, false
+	}
+	d, ok := history.dates[path]
+	return d, ok
+}
+
+// repoPath converts file to the slash-separated form git stores in trees
+func repoPath(root, file string) (string, bool) {
+	abs, err := filepath.Abs(file)
+	if err != nil {
+		return "", false
+	}
+	rel, err := filepath.Rel(root, abs)
+	if err != nil || !filepath.IsLocal(rel) {
+		return "", false
+	}
+	return filepath.ToSlash(rel), true
+}
@@ -0,0 +1,326 @@
+package content
+
+import (
+	"fmt"
+	"html/template"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"journal/internal/render"
+)
+
+// Severity ranks lint problems; errors break or hide content, warnings
+// only degrade it
+type Severity int
+
+const (
+	Warning Severity = iota
+	Error
+)
+
+func (s Severity) String() string {
+	if s == Error {
+		return "error"
+	}
+	return "warning"
+}
+
+// Problem is one issue Lint found in a file
+type Problem struct {
+	File     string
+	Severity Severity
+	Message  string
+}
+
+func (p Problem) String() string {
+	return fmt.Sprintf("%s: %s: %s", p.File, p.Severity, p.Message)
+}
+
+// LintOptions describes the parts of the site Lint checks content against
+type LintOptions struct {
+	// Dir is the content directory
+	Dir string
+	// Sections lists the sections to check, as in Options
+	Sections []string
+	// Categories lists the shelf categories the site renders; items without
+	// one are filed under the first
+	Categories []string
+	// StaticDir is served under /static/; files in it nothing refers to
+	// are reported, except stylesheets, which are built and linked by hand
+	StaticDir string
+	// TemplatesDir is searched for /static/ references too
+	TemplatesDir string
+}
+
+// lintEntry is what Lint needs from an entry of any section
+type lintEntry struct {
+	slug     string
+	title    string
+	image    string
+	category string
+	series   string
+	html     template.HTML
+}
+
+// lintSection loads one section's files for Lint; path maps a slug to
+// the entry's page, or is nil for sections without one
+type lintSection struct {
+	name       string
+	glob       string
+	path       func(slug string) string
+	load       func(src source, file string) (lintEntry, error)
+	needsImage bool
+	checks     func(file string, meta map[string]any, e lintEntry) []Problem
+}
+
+// linkPattern finds the targets of links and images in rendered markdown
+var linkPattern = regexp.MustCompile(`(?:href|src)="([^"]*)"`)
+
+// staticPattern finds /static/ references in templates
+var staticPattern = regexp.MustCompile(`/static/[^"'\s)]+`)
+
+// Lint loads every section file by file, so one broken file does not hide
+// the rest, and reports what would break or silently degrade when served:
+// unparseable frontmatter and dates, missing titles, duplicate slugs,
+// unknown shelf categories, empty images, missing summaries, internal
+// links to entries or files that do not exist and unreferenced assets.
+// Problems are sorted by file.
+func Lint(opts LintOptions) []Problem {
+	var problems []Problem
+	report := func(file string, severity Severity, format string, args ...any) {
+		problems = append(problems, Problem{File: file, Severity: severity, Message: fmt.Sprintf(format, args...)})
+	}
+
+	// Pages and files that exist, and the links to check against them
+	pages := make(map[string]string) // page path -> file
+	assets := make(map[string]bool)  // referenced paths under /static/
+	type link struct{ file, target string }
+	var links []link
+
+	src := Options{Dir: opts.Dir}.source()
+	for _, section := range lintSections(opts) {
+		if !(Options{Sections: opts.Sections}).enabled(section.name) {
+			continue
+		}
+		files, err := fs.Glob(src.fsys, section.glob)
+		if err != nil {
+			report(section.glob, Error, "%v", err)
+			continue
+		}
+		for _, name := range files {
+			file := src.path(name)
+			data, err := fs.ReadFile(src.fsys, name)
+			if err != nil {
+				report(file, Error, "%v", err)
+				continue
+			}
+			// Decoding into a map as well shows which keys were set at all
+			var meta map[string]any
+			if _, err := parseFrontmatter(file, string(data), &meta); err != nil {
+				report(file, Error, "%s", strings.TrimPrefix(err.Error(), file+": "))
+				continue
+			}
+			e, err := section.load(src, name)
+			if err != nil {
+				report(file, Error, "%s", strings.TrimPrefix(err.Error(), file+": "))
+				continue
+			}
+
+			if _, ok := meta["date"]; !ok {
+				report(file, Error, "no date; the binary's embedded content cannot fall back on git history or mtimes")
+			}
+			if e.title == "Untitled" {
+				report(file, Error, "no title in frontmatter or as a heading")
+			}
+			if image, ok := meta["image"]; section.needsImage && strings.TrimSpace(e.image) == "" {
+				report(file, Warning, "no image; the pixel has nothing to show")
+			} else if ok && (image == nil || strings.TrimSpace(fmt.Sprint(image)) == "") {
+				report(file, Warning, "image is empty; set it or remove the key")
+			}
+			if strings.HasPrefix(e.image, "/") {
+				links = append(links, link{file, e.image})
+			}
+			if section.checks != nil {
+				problems = append(problems, section.checks(file, meta, e)...)
+			}
+
+			if section.path != nil {
+				page := section.path(e.slug)
+				if other, ok := pages[page]; ok {
+					report(file, Error, "slug %q is also used by %s", e.slug, other)
+				}
+				pages[page] = file
+			}
+			if e.series != "" {
+				pages["/series/"+render.Slugify(e.series)] = file
+			}
+			for _, m := range linkPattern.FindAllStringSubmatch(string(e.html), -1) {
+				links = append(links, link{file, m[1]})
+			}
+		}
+	}
+
+	for _, l := range links {
+		target, ok := internalPath(l.target)
+		if !ok {
+			continue
+		}
+		if asset, ok := strings.CutPrefix(target, "/static/"); ok {
+			assets[asset] = true
+			if _, err := os.Stat(filepath.Join(opts.StaticDir, filepath.FromSlash(asset))); err != nil {
+				report(l.file, Error, "broken link to %s: no such file", l.target)
+			}
+			continue
+		}
+		if isEntryPath(target) && pages[target] == "" {
+			report(l.file, Error, "broken link to %s: no such entry", l.target)
+		}
+	}
+
+	problems = append(problems, lintAssets(opts, assets)...)
+
+	sort.SliceStable(problems, func(i, j int) bool {
+		return problems[i].File < problems[j].File
+	})
+	return problems
+}
+
+// lintSections describes how to load and check each section
+func lintSections(opts LintOptions) []lintSection {
+	var defaultCategory string
+	if len(opts.Categories) > 0 {
+		defaultCategory = opts.Categories[0]
+	}
+
+	requireSummary := func(file string, meta map[string]any, e lintEntry) []Problem {
+		if summary, _ := meta["summary"].(string); strings.TrimSpace(summary) == "" {
+			return []Problem{{File: file, Severity: Warning, Message: "no summary; listings show the first paragraph instead"}}
+		}
+		return nil
+	}
+
+	return []lintSection{
+		{
+			name: "articles",
+			glob: articlesGlob,
+			path: func(slug string) string { return "/articles/" + slug },
+			load: func(src source, file string) (lintEntry, error) {
+				a, err := loadArticle(src, file)
+				return lintEntry{slug: a.Slug, title: a.Title, image: a.Image, series: a.Series, html: a.HTML}, err
+			},
+			checks: requireSummary,
+		},
+		{
+			name: "fragments",
+			glob: fragmentsGlob,
+			path: func(slug string) string { return "/fragments/" + slug },
+			load: func(src source, file string) (lintEntry, error) {
+				f, err := loadFragment(src, file)
+				return lintEntry{slug: f.Slug, title: f.Title, image: f.Image, html: f.HTML}, err
+			},
+		},
+		{
+			name: "shelf",
+			glob: shelfGlob,
+			path: func(slug string) string { return "/shelf/" + slug },
+			load: func(src source, file string) (lintEntry, error) {
+				i, err := loadShelfItem(src, file, defaultCategory)
+				return lintEntry{slug: i.Slug, title: i.Title, category: i.Category, html: i.HTML}, err
+			},
+			checks: func(file string, meta map[string]any, e lintEntry) []Problem {
+				problems := requireSummary(file, meta, e)
+				if !slices.Contains(opts.Categories, e.category) {
+					problems = append(problems, Problem{File: file, Severity: Error, Message: fmt.Sprintf(
+						"unknown category %q; the shelf only shows %s", e.category, strings.Join(opts.Categories, ", "))})
+				}
+				return problems
+			},
+		},
+		{
+			name: "pixels",
+			glob: pixelsGlob,
+			path: func(slug string) string { return "/pixels/" + slug },
+			load: func(src source, file string) (lintEntry, error) {
+				p, err := loadPixel(src, file)
+				return lintEntry{slug: p.Slug, title: p.Title, image: p.Image, html: p.HTML}, err
+			},
+			needsImage: true,
+		},
+		{
+			name: "about",
+			glob: aboutGlob,
+			load: func(src source, file string) (lintEntry, error) {
+				a, err := LoadAbout(Options{Dir: opts.Dir})
+				// The about page has no title of its own to check
+				return lintEntry{slug: a.Slug, title: "About", image: a.Image, html: a.HTML}, err
+			},
+		},
+	}
+}
+
+// internalPath returns the cleaned path of a link into this site, without
+// query or fragment; external, relative and in-page links are skipped
+func internalPath(target string) (string, bool) {
+	if !strings.HasPrefix(target, "/") || strings.HasPrefix(target, "//") {
+		return "", false
+	}
+	target, _, _ = strings.Cut(target, "#")
+	target, _, _ = strings.Cut(target, "?")
+	return path.Clean(target), true
+}
+
+// isEntryPath reports whether p names a single entry or series page, as
+// opposed to a listing or a feed under the same prefix
+func isEntryPath(p string) bool {
+	for _, prefix := range []string{"/articles/", "/fragments/", "/shelf/", "/pixels/", "/series/"} {
+		if rest, ok := strings.CutPrefix(p, prefix); ok {
+			return rest != "" && !strings.ContainsAny(rest, "/.")
+		}
+	}
+	return false
+}
+
+// lintAssets reports files under StaticDir that neither content nor
+// templates refer to
+func lintAssets(opts LintOptions, referenced map[string]bool) []Problem {
+	if opts.StaticDir == "" {
+		return nil
+	}
+	if opts.TemplatesDir != "" {
+		filepath.WalkDir(opts.TemplatesDir, func(file string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() {
+				return err
+			}
+			data, err := os.ReadFile(file)
+			if err != nil {
+				return err
+			}
+			for _, ref := range staticPattern.FindAllString(string(data), -1) {
+				referenced[strings.TrimPrefix(ref, "/static/")] = true
+			}
+			return nil
+		})
+	}
+
+	var problems []Problem
+	filepath.WalkDir(opts.StaticDir, func(file string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || filepath.Ext(file) == ".css" {
+			return err
+		}
+		rel, err := filepath.Rel(opts.StaticDir, file)
+		if err != nil {
+			return err
+		}
+		if !referenced[filepath.ToSlash(rel)] {
+			problems = append(problems, Problem{File: filepath.ToSlash(file), Severity: Warning, Message: "unreferenced asset"})
+		}
+		return nil
+	})
+	return problems
+}
@@ -2,7 +2,7 @@ package content
 
 import (
     "html/template"
-    "io/ioutil"
+    "io/fs"
     "path/filepath"
     "strings"
 
@@ -10,8 +10,9 @@ import (
     "journal/internal/render"
 )
 
-func LoadPixels() ([]models.Pixel, error) {
-    files, err := filepath.Glob("internal/content/pixels/*.md")
+func LoadPixels(opts Options) ([]models.Pixel, error) {
+    src := opts.source()
+    files, err := fs.Glob(src.fsys, pixelsGlob)
     if err != nil {
         return nil, err
     }
@@ -19,33 +20,55 @@ func LoadPixels() ([]models.Pixel, error) {
     var pixels []models.Pixel
 
     for _, file := range files {
-        data, err := ioutil.ReadFile(file)
+        pixel, err := loadPixel(src, file)
         if err != nil {
             return nil, err
         }
+        pixels = append(pixels, pixel)
+    }
 
-        md := string(data)
-        md, frontmatter := parseFrontmatter(md)
+    return pixels, nil
 
-        html, _, err := render.MarkdownToHTML(md)
-        if err != nil {
-            return nil, err
-        }
+}
 
-        slug := strings.TrimSuffix(filepath.Base(file), ".md")
-        title := extractTitle(md, frontmatter)
-        image := fetchImageUrl(frontmatter)
-        date := extractDate(file, frontmatter)
-
-        pixels = append(pixels, models.Pixel{
-            Slug:  slug,
-            Title: title,
-            Image: image,
-            Date:  date,
-            HTML:  template.HTML(html),
-        })
+// loadPixel reads and renders a single pixel file
+func loadPixel(src source, file string) (models.Pixel, error) {
+    data, err := fs.ReadFile(src.fsys, file)
+    if err != nil {
+        return models.Pixel{}, err
     }
 
-    return pixels, nil
+    var meta pixelMeta
+    md, err := parseFrontmatter(src.path(file), string(data), &meta)
+    if err != nil {
+        return models.Pixel{}, err
+    }
+
+    doc := render.Parse(md)
+    html, _, err := doc.HTML()
+    if err != nil {
+        return models.Pixel{}, err
+    }
+
+    slug := strings.TrimSuffix(filepath.Base(file), ".md")
+    title := extractTitle(md, meta.Title)
+    image := meta.Image
+    date, err := extractDate(src, file, meta.Date)
+    if err != nil {
+        return models.Pixel{}, err
+    }
+    updated := extractUpdated(src, file, meta.Updated, date)
+    publishAt := extractPublishAt(meta.PublishAt, date)
 
+    return models.Pixel{
+        Slug:      slug,
+        Title:     title,
+        Image:     image,
+        Date:      date,
+        Updated:   updated,
+        HTML:      template.HTML(html),
+        PlainText: doc.Stats().Plain,
+        Draft:     meta.Draft,
+        PublishAt: publishAt,
+    }, nil
 }
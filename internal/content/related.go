@@ -0,0 +1,162 @@
+package content
+
+import (
+	"math"
+	"sort"
+
+	"journal/internal/models"
+	"journal/internal/search"
+)
+
+const (
+	// relatedLimit is how many related entries a detail page shows
+	relatedLimit = 3
+
+	// relatedMinScore drops matches too weak to be worth suggesting
+	relatedMinScore = 0.05
+
+	// relatedTagShare is the part of the score from shared tags; the rest
+	// comes from text similarity
+	relatedTagShare = 0.6
+
+	// relatedTitleWeight counts a title term as this many body occurrences
+	relatedTitleWeight = 3
+)
+
+type relatedDoc struct {
+	entry models.Related
+	tags  []string
+	terms map[string]float64 // tf-idf weights, unit length
+}
+
+// Related returns the entries most like the one at section/slug
+func (s *Store) Related(section, slug string) []models.Related {
+	s.refresh()
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.related[section+"/"+slug]
+}
+
+// indexRelated scores every article and fragment against every other by
+// shared tags, weighted so rare tags count for more, and by the cosine
+// similarity of their tf-idf term vectors; called from index
+func (s *Store) indexRelated() {
+	var docs []relatedDoc
+	for _, a := range s.liveArticles {
+		docs = append(docs, newRelatedDoc(models.Related{
+			Section: "articles", Slug: a.Slug, Title: a.Title,
+			URL: "/articles/" + a.Slug, Date: a.Date,
+		}, a.Tags, a.PlainText))
+	}
+	for _, f := range s.liveFragments {
+		docs = append(docs, newRelatedDoc(models.Related{
+			Section: "fragments", Slug: f.Slug, Title: f.Title,
+			URL: "/fragments/" + f.Slug, Date: f.Date,
+		}, f.Tags, f.PlainText))
+	}
+
+	n := float64(len(docs))
+	tagDF := make(map[string]int)
+	termDF := make(map[string]int)
+	for _, d := range docs {
+		for _, t := range d.tags {
+			tagDF[t]++
+		}
+		for term := range d.terms {
+			termDF[term]++
+		}
+	}
+	idf := func(df int) float64 { return math.Log(1 + n/float64(df)) }
+
+	for _, d := range docs {
+		var norm float64
+		for term, tf := range d.terms {
+			w := tf * idf(termDF[term])
+			d.terms[term] = w
+			norm += w * w
+		}
+		if norm == 0 {
+			continue
+		}
+		norm = math.Sqrt(norm)
+		for term := range d.terms {
+			d.terms[term] /= norm
+		}
+	}
+
+	s.related = make(map[string][]models.Related, len(docs))
+	for i, d := range docs {
+		var matches []models.Related
+		for j, other := range docs {
+			if i == j {
+				continue
+			}
+			score := relatedTagShare*tagOverlap(d.tags, other.tags, tagDF, idf) +
+				(1-relatedTagShare)*cosine(d.terms, other.terms)
+			if score < relatedMinScore {
+				continue
+			}
+			match := other.entry
+			match.Score = score
+			matches = append(matches, match)
+		}
+		sort.Slice(matches, func(a, b int) bool {
+			if matches[a].Score != matches[b].Score {
+				return matches[a].Score > matches[b].Score
+			}
+			return matches[a].Date.After(matches[b].Date)
+		})
+		if len(matches) > relatedLimit {
+			matches = matches[:relatedLimit]
+		}
+		s.related[d.entry.Section+"/"+d.entry.Slug] = matches
+	}
+}
+
+func newRelatedDoc(entry models.Related, tags []string, text string) relatedDoc {
+	terms := make(map[string]float64)
+	for _, term := range search.Tokenize(entry.Title) {
+		terms[term] += relatedTitleWeight
+	}
+	for _, term := range search.Tokenize(text) {
+		terms[term]++
+	}
+	return relatedDoc{entry: entry, tags: tags, terms: terms}
+}
+
+// tagOverlap is the weighted Jaccard similarity of two tag sets: the idf of
+// the shared tags over the idf of all tags either carries
+func tagOverlap(a, b []string, df map[string]int, idf func(int) float64) float64 {
+	in := make(map[string]bool, len(a))
+	for _, t := range a {
+		in[t] = true
+	}
+
+	var shared, union float64
+	for _, t := range a {
+		union += idf(df[t])
+	}
+	for _, t := range b {
+		if in[t] {
+			shared += idf(df[t])
+		} else {
+			union += idf(df[t])
+		}
+	}
+	if union == 0 {
+		return 0
+	}
+	return shared / union
+}
+
+// cosine returns the dot product of two unit-length term vectors
+func cosine(a, b map[string]float64) float64 {
+	if len(b) < len(a) {
+		a, b = b, a
+	}
+	var dot float64
+	for term, w := range a {
+		dot += w * b[term]
+	}
+	return dot
+}
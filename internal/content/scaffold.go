@@ -0,0 +1,169 @@
+package content
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"journal/internal/render"
+)
+
+// Sections that Scaffold can create entries in
+var ScaffoldSections = []string{"article", "fragment", "shelf", "pixel"}
+
+// ScaffoldOptions fills in section-specific frontmatter for Scaffold
+type ScaffoldOptions struct {
+	// Dir is the content directory
+	Dir string
+
+	// Date is the entry's date; today when zero
+	Date time.Time
+
+	// Category and Author apply to shelf items; Category is required
+	Category string
+	Author   string
+
+	// Image is the pixel's picture
+	Image string
+}
+
+// Scaffold writes a new draft entry titled title into section, laid out
+// like the existing ones, and returns its path. The slug follows the same
+// rules as heading ids; pixels are numbered after the highest existing one.
+// It refuses to overwrite or to reuse a slug already taken in the section.
+func Scaffold(section, title string, opts ScaffoldOptions) (string, error) {
+	title = strings.TrimSpace(title)
+	if title == "" {
+		return "", errors.New("title is empty")
+	}
+	// Slugify falls back to a placeholder for titles without letters or
+	// digits, which would make every such entry collide
+	if strings.IndexFunc(title, isSlugRune) < 0 {
+		return "", fmt.Errorf("title %q has no letters or digits to make a slug from", title)
+	}
+	slug := render.Slugify(title)
+	day := opts.Date
+	if day.IsZero() {
+		day = time.Now()
+	}
+	date := day.Format("2006-01-02")
+
+	// Common keys first, in the order the existing entries use
+	fields := [][2]string{{"title", title}}
+	var file, glob string
+	switch section {
+	case "article":
+		glob = articlesGlob
+		file = filepath.Join(opts.Dir, filepath.Dir(articlesGlob), slug+".md")
+		fields = append(fields, [2]string{"date", date},
+			[2]string{"tags", ""}, [2]string{"summary", ""})
+
+	case "fragment":
+		glob = fragmentsGlob
+		file = filepath.Join(opts.Dir, filepath.Dir(fragmentsGlob), slug+".md")
+		fields = append(fields, [2]string{"date", date},
+			[2]string{"tags", ""})
+
+	case "shelf":
+		category := strings.ToLower(strings.TrimSpace(opts.Category))
+		if category == "" {
+			return "", errors.New("shelf items need a category")
+		}
+		glob = shelfGlob
+		file = filepath.Join(opts.Dir, filepath.Dir(filepath.Dir(shelfGlob)), category, slug+".md")
+		fields = append(fields, [2]string{"sub-title", ""}, [2]string{"author", opts.Author},
+			[2]string{"date", date}, [2]string{"category", category},
+			[2]string{"tags", ""}, [2]string{"summary", ""})
+
+	case "pixel":
+		if strings.TrimSpace(opts.Image) == "" {
+			return "", errors.New("pixels need an image")
+		}
+		n, err := nextPixel(opts.Dir)
+		if err != nil {
+			return "", err
+		}
+		glob = pixelsGlob
+		file = filepath.Join(opts.Dir, filepath.Dir(pixelsGlob), fmt.Sprintf("%03d.md", n))
+		fields = append(fields, [2]string{"date", date},
+			[2]string{"image", opts.Image})
+
+	default:
+		return "", fmt.Errorf("unknown section %q: want %s", section, strings.Join(ScaffoldSections, ", "))
+	}
+
+	// Shelf slugs share one namespace across their category directories
+	slug = strings.TrimSuffix(filepath.Base(file), ".md")
+	existing, err := filepath.Glob(filepath.Join(opts.Dir, glob))
+	if err != nil {
+		return "", err
+	}
+	for _, other := range existing {
+		if strings.TrimSuffix(filepath.Base(other), ".md") == slug {
+			return "", fmt.Errorf("slug %q is already used by %s", slug, filepath.ToSlash(other))
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString("---\n")
+	for _, field := range fields {
+		value := field[1]
+		if field[0] != "date" {
+			value = yamlScalar(value)
+		}
+		b.WriteString(field[0] + ": " + value + "\n")
+	}
+	b.WriteString("draft: true\n---\n\n")
+
+	if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+		return "", err
+	}
+	f, err := os.OpenFile(file, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		return "", err
+	}
+	if _, err := f.WriteString(b.String()); err != nil {
+		f.Close()
+		return "", err
+	}
+	return filepath.ToSlash(file), f.Close()
+}
+
+// nextPixel returns one more than the highest numbered pixel
+func nextPixel(dir string) (int, error) {
+	files, err := filepath.Glob(filepath.Join(dir, pixelsGlob))
+	if err != nil {
+		return 0, err
+	}
+	highest := 0
+	for _, file := range files {
+		n, err := strconv.Atoi(strings.TrimSuffix(filepath.Base(file), ".md"))
+		if err == nil && n > highest {
+			highest = n
+		}
+	}
+	return highest + 1, nil
+}
+
+// isSlugRune reports whether Slugify keeps r
+func isSlugRune(r rune) bool {
+	return r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9'
+}
+
+// yamlScalar renders s as a YAML value, quoting only when it needs it
+func yamlScalar(s string) string {
+	if s == "" {
+		return ""
+	}
+	out, err := yaml.Marshal(s)
+	if err != nil {
+		return strconv.Quote(s)
+	}
+	return strings.TrimSuffix(string(out), "\n")
+}
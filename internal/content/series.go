@@ -0,0 +1,49 @@
+package content
+
+import (
+	"sort"
+
+	"journal/internal/models"
+	"journal/internal/render"
+)
+
+// Series returns the series with the given name or slug, parts in reading order
+func (s *Store) Series(name string) (models.Series, bool) {
+	s.refresh()
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	series, ok := s.series[render.Slugify(name)]
+	return series, ok
+}
+
+// indexSeries groups articles by series, ordered by seriesOrder and then by
+// date for parts without one; called from index
+func (s *Store) indexSeries() {
+	s.series = make(map[string]models.Series)
+	for _, a := range s.liveArticles {
+		if a.Series == "" {
+			continue
+		}
+		slug := render.Slugify(a.Series)
+		series, ok := s.series[slug]
+		if !ok {
+			series = models.Series{Name: a.Series, Slug: slug}
+		}
+		series.Parts = append(series.Parts, a)
+		s.series[slug] = series
+	}
+
+	for _, series := range s.series {
+		parts := series.Parts
+		sort.SliceStable(parts, func(i, j int) bool {
+			oi, oj := parts[i].SeriesOrder, parts[j].SeriesOrder
+			switch {
+			case oi != oj && oi != 0 && oj != 0:
+				return oi < oj
+			case oi != oj:
+				return oj == 0 // ordered parts come first
+			}
+			return parts[i].Date.Before(parts[j].Date)
+		})
+	}
+}
@@ -2,7 +2,7 @@ package content
 
 import (
 	"html/template"
-	"io/ioutil"
+	"io/fs"
 	"path/filepath"
 	"strings"
 
@@ -10,8 +10,9 @@ import (
 	"journal/internal/render"
 )
 
-func LoadShelfItems() ([]models.ShelfItem, error) {
-	files, err := filepath.Glob("internal/content/shelf/*/*.md")
+func LoadShelfItems(opts Options) ([]models.ShelfItem, error) {
+	src := opts.source()
+	files, err := fs.Glob(src.fsys, shelfGlob)
 	if err != nil {
 		return nil, err
 	}
@@ -19,37 +20,63 @@ func LoadShelfItems() ([]models.ShelfItem, error) {
 	var items []models.ShelfItem
 
 	for _, file := range files {
-		data, err := ioutil.ReadFile(file)
+		item, err := loadShelfItem(src, file, opts.DefaultCategory)
 		if err != nil {
 			return nil, err
 		}
+		items = append(items, item)
+	}
 
-		md := string(data)
-		md, frontmatter := parseFrontmatter(md)
+	return items, nil
+}
 
-		html, _, err := render.MarkdownToHTML(md)
-		if err != nil {
-			return nil, err
-		}
+// loadShelfItem reads and renders a single shelf entry
+func loadShelfItem(src source, file, defaultCategory string) (models.ShelfItem, error) {
+	data, err := fs.ReadFile(src.fsys, file)
+	if err != nil {
+		return models.ShelfItem{}, err
+	}
 
-		slug := strings.TrimSuffix(filepath.Base(file), ".md")
-		title := extractTitle(md, frontmatter)
-		summary := extractSummary(md, frontmatter)
-		date := extractDate(file, frontmatter)
-		category := frontmatter["category"]
-		if category == "" {
-			category = "books"
-		}
+	var meta shelfMeta
+	md, err := parseFrontmatter(src.path(file), string(data), &meta)
+	if err != nil {
+		return models.ShelfItem{}, err
+	}
 
-		items = append(items, models.ShelfItem{
-			Slug:     slug,
-			Title:    title,
-			Summary:  summary,
-			Date:     date,
-			Category: strings.ToLower(category),
-			HTML:     template.HTML(html),
-		})
+	doc := render.Parse(md)
+	html, _, err := doc.HTML()
+	if err != nil {
+		return models.ShelfItem{}, err
 	}
 
-	return items, nil
+	slug := strings.TrimSuffix(filepath.Base(file), ".md")
+	title := extractTitle(md, meta.Title)
+	summary := extractSummary(doc, meta.Summary)
+	date, err := extractDate(src, file, meta.Date)
+	if err != nil {
+		return models.ShelfItem{}, err
+	}
+	updated := extractUpdated(src, file, meta.Updated, date)
+	publishAt := extractPublishAt(meta.PublishAt, date)
+	stats := doc.Stats()
+	category := meta.Category
+	if category == "" {
+		category = defaultCategory
+	}
+
+	return models.ShelfItem{
+		Slug:        slug,
+		Title:       title,
+		Summary:     summary,
+		Date:        date,
+		Updated:     updated,
+		Category:    strings.ToLower(category),
+		HTML:        template.HTML(html),
+		PlainText:   stats.Plain,
+		WordCount:   stats.Words,
+		ReadingTime: stats.ReadingTime(),
+		Tags:        []string(meta.Tags),
+		Draft:       meta.Draft,
+		PublishAt:   publishAt,
+	}, nil
 }
@@ -0,0 +1,643 @@
+package content
+
+import (
+	"cmp"
+	"errors"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"journal/internal/gitlog"
+	"journal/internal/models"
+	"journal/internal/search"
+)
+
+// File layout of each section under the content directory
+const (
+	articlesGlob  = "articles/*.md"
+	fragmentsGlob = "fragments/*.md"
+	shelfGlob     = "shelf/*/*.md"
+	pixelsGlob    = "pixels/*.md"
+	aboutGlob     = "about/about.md"
+)
+
+// Store holds every section loaded and rendered once at startup,
+// indexed so handlers never touch the filesystem on a request.
+type Store struct {
+	mu   sync.RWMutex
+	opts Options
+
+	// Everything loaded from disk, including drafts and scheduled entries
+	articles  []models.Article
+	fragments []models.Fragment
+	shelf     []models.ShelfItem
+	pixels    []models.Pixel
+	about     models.About
+
+	// What readers may see right now; the maps below index only these
+	liveArticles  []models.Article
+	liveFragments []models.Fragment
+	liveShelf     []models.ShelfItem
+	livePixels    []models.Pixel
+
+	// nextPublish is the earliest future publish time among hidden entries,
+	// at which point the indexes are rebuilt
+	nextPublish time.Time
+
+	// Positions of each slug in the live lists
+	articleIndex  map[string]int
+	fragmentIndex map[string]int
+	pixelIndex    map[string]int
+
+	shelfBySlug map[string]models.ShelfItem
+
+	articleTags     map[string]int
+	articlesByYear  map[int][]models.Article
+	articleYears    []int
+	fragmentsByYear map[int][]models.Fragment
+	fragmentYears   []int
+
+	tags    []models.Tag
+	archive []models.ArchiveYear
+	related map[string][]models.Related // keyed by section/slug
+	series  map[string]models.Series    // keyed by series slug
+	search  *search.Index
+}
+
+// Options controls where the store loads from and which entries it exposes
+type Options struct {
+	// FS holds the content, one subdirectory per section; when nil it is
+	// read from Dir
+	FS fs.FS
+
+	// Dir is the content directory on disk, if the content lives on disk:
+	// git history is looked up there and Reload resolves paths against it.
+	// Leave it empty when FS is compiled into the binary.
+	Dir string
+
+	// Sections lists the sections to load, by directory name; every
+	// section when nil
+	Sections []string
+
+	// Drafts lists drafts and future-dated entries as if they were published
+	Drafts bool
+
+	// DefaultCategory files shelf items whose frontmatter names no category
+	DefaultCategory string
+}
+
+// enabled reports whether section is loaded
+func (o Options) enabled(section string) bool {
+	return o.Sections == nil || slices.Contains(o.Sections, section)
+}
+
+// source returns where the content files are read from
+func (o Options) source() source {
+	if o.FS != nil {
+		return source{fsys: o.FS, dir: o.Dir}
+	}
+	dir := cmp.Or(o.Dir, ".")
+	return source{fsys: os.DirFS(dir), dir: dir}
+}
+
+// source is a content filesystem, which may be compiled into the binary,
+// and the directory it mirrors when it is read from disk
+type source struct {
+	fsys fs.FS
+	dir  string
+}
+
+// path names file, which is relative to the content root, in messages
+func (src source) path(file string) string {
+	if src.dir == "" {
+		return file
+	}
+	return filepath.ToSlash(filepath.Join(src.dir, file))
+}
+
+// history returns the git dates of file, when the content is on disk
+func (src source) history(file string) (gitlog.Dates, bool) {
+	if src.dir == "" {
+		return gitlog.Dates{}, false
+	}
+	return fileHistory(src.dir, filepath.Join(src.dir, filepath.FromSlash(file)))
+}
+
+// publishable is implemented by every section that supports drafts and scheduling
+type publishable interface {
+	IsPublished(now time.Time) bool
+	PublishTime() time.Time
+}
+
+var store *Store
+
+// InitStore loads every section once at startup
+func InitStore(opts Options) error {
+	s, err := NewStore(opts)
+	if err != nil {
+		return err
+	}
+	store = s
+	return nil
+}
+
+// Current returns the store built by InitStore
+func Current() *Store {
+	return store
+}
+
+// NewStore loads, renders and indexes the enabled content sections
+func NewStore(opts Options) (*Store, error) {
+	s := &Store{opts: opts}
+	var err error
+	if opts.enabled("articles") {
+		if s.articles, err = LoadArticles(opts); err != nil {
+			return nil, err
+		}
+	}
+	if opts.enabled("fragments") {
+		if s.fragments, err = LoadFragments(opts); err != nil {
+			return nil, err
+		}
+	}
+	if opts.enabled("shelf") {
+		if s.shelf, err = LoadShelfItems(opts); err != nil {
+			return nil, err
+		}
+	}
+	if opts.enabled("pixels") {
+		if s.pixels, err = LoadPixels(opts); err != nil {
+			return nil, err
+		}
+	}
+	if opts.enabled("about") {
+		if s.about, err = LoadAbout(opts); err != nil {
+			return nil, err
+		}
+	}
+
+	sortNewestFirst(s.articles, func(a models.Article) time.Time { return a.Date })
+	sortNewestFirst(s.fragments, func(f models.Fragment) time.Time { return f.Date })
+	sortNewestFirst(s.shelf, func(i models.ShelfItem) time.Time { return i.Date })
+	sortNewestFirst(s.pixels, func(p models.Pixel) time.Time { return p.Date })
+	s.index()
+	return s, nil
+}
+
+// Reload re-reads a single changed, created or removed content file, given
+// by its path on disk, and updates only the affected entry. Files outside
+// any section are ignored. It reports whether the store changed.
+func (s *Store) Reload(file string) (bool, error) {
+	src := s.opts.source()
+	if src.dir == "" {
+		return false, nil
+	}
+	rel, err := filepath.Rel(src.dir, file)
+	if err != nil || !filepath.IsLocal(rel) {
+		return false, nil
+	}
+	file = filepath.ToSlash(rel)
+	slug := strings.TrimSuffix(filepath.Base(file), ".md")
+	_, statErr := fs.Stat(src.fsys, file)
+	removed := errors.Is(statErr, fs.ErrNotExist)
+
+	switch {
+	case s.matches("articles", articlesGlob, file):
+		var article *models.Article
+		if !removed {
+			a, err := loadArticle(src, file)
+			if err != nil {
+				return false, err
+			}
+			article = &a
+		}
+		s.mu.Lock()
+		s.articles = upsert(s.articles, slug, article, func(a models.Article) string { return a.Slug })
+		sortNewestFirst(s.articles, func(a models.Article) time.Time { return a.Date })
+		s.index()
+		s.mu.Unlock()
+
+	case s.matches("fragments", fragmentsGlob, file):
+		var fragment *models.Fragment
+		if !removed {
+			f, err := loadFragment(src, file)
+			if err != nil {
+				return false, err
+			}
+			fragment = &f
+		}
+		s.mu.Lock()
+		s.fragments = upsert(s.fragments, slug, fragment, func(f models.Fragment) string { return f.Slug })
+		sortNewestFirst(s.fragments, func(f models.Fragment) time.Time { return f.Date })
+		s.index()
+		s.mu.Unlock()
+
+	case s.matches("shelf", shelfGlob, file):
+		var item *models.ShelfItem
+		if !removed {
+			i, err := loadShelfItem(src, file, s.opts.DefaultCategory)
+			if err != nil {
+				return false, err
+			}
+			item = &i
+		}
+		s.mu.Lock()
+		s.shelf = upsert(s.shelf, slug, item, func(i models.ShelfItem) string { return i.Slug })
+		sortNewestFirst(s.shelf, func(i models.ShelfItem) time.Time { return i.Date })
+		s.index()
+		s.mu.Unlock()
+
+	case s.matches("pixels", pixelsGlob, file):
+		var pixel *models.Pixel
+		if !removed {
+			p, err := loadPixel(src, file)
+			if err != nil {
+				return false, err
+			}
+			pixel = &p
+		}
+		s.mu.Lock()
+		s.pixels = upsert(s.pixels, slug, pixel, func(p models.Pixel) string { return p.Slug })
+		sortNewestFirst(s.pixels, func(p models.Pixel) time.Time { return p.Date })
+		s.index()
+		s.mu.Unlock()
+
+	case s.matches("about", aboutGlob, file):
+		about, err := LoadAbout(s.opts)
+		if err != nil {
+			return false, err
+		}
+		s.mu.Lock()
+		s.about = about
+		s.mu.Unlock()
+
+	default:
+		return false, nil
+	}
+
+	return true, nil
+}
+
+// matches reports whether file, relative to the content root, belongs to
+// section, which must be enabled
+func (s *Store) matches(section, pattern, file string) bool {
+	ok, _ := path.Match(pattern, file)
+	return ok && s.opts.enabled(section)
+}
+
+// upsert returns a copy of items with the entry for slug replaced by item,
+// or dropped when item is nil. Readers holding the old slice are unaffected.
+func upsert[T any](items []T, slug string, item *T, slugOf func(T) string) []T {
+	out := make([]T, 0, len(items)+1)
+	for _, existing := range items {
+		if slugOf(existing) != slug {
+			out = append(out, existing)
+		}
+	}
+	if item != nil {
+		out = append(out, *item)
+	}
+	return out
+}
+
+// sortNewestFirst orders items by date, newest first
+func sortNewestFirst[T any](items []T, dateOf func(T) time.Time) {
+	sort.SliceStable(items, func(i, j int) bool {
+		return dateOf(items[i]).After(dateOf(items[j]))
+	})
+}
+
+// groupByYear buckets items, already sorted newest first, by the year of
+// their date; years lists the buckets newest first
+func groupByYear[T any](items []T, dateOf func(T) time.Time) (byYear map[int][]T, years []int) {
+	byYear = make(map[int][]T)
+	for _, item := range items {
+		year := dateOf(item).Year()
+		if _, ok := byYear[year]; !ok {
+			years = append(years, year)
+		}
+		byYear[year] = append(byYear[year], item)
+	}
+	return byYear, years
+}
+
+// index rebuilds the live lists and lookup maps from the sorted sections.
+// Callers must hold s.mu for writing once the store is shared.
+func (s *Store) index() {
+	now := time.Now()
+	s.nextPublish = time.Time{}
+	s.liveArticles = live(s, s.articles, now)
+	s.liveFragments = live(s, s.fragments, now)
+	s.liveShelf = live(s, s.shelf, now)
+	s.livePixels = live(s, s.pixels, now)
+
+	s.articleIndex = make(map[string]int, len(s.liveArticles))
+	s.articleTags = make(map[string]int)
+	for i, a := range s.liveArticles {
+		s.articleIndex[a.Slug] = i
+		for _, tag := range a.Tags {
+			s.articleTags[tag]++
+		}
+	}
+	s.articlesByYear, s.articleYears = groupByYear(s.liveArticles, func(a models.Article) time.Time { return a.Date })
+
+	s.fragmentIndex = make(map[string]int, len(s.liveFragments))
+	for i, f := range s.liveFragments {
+		s.fragmentIndex[f.Slug] = i
+	}
+	s.fragmentsByYear, s.fragmentYears = groupByYear(s.liveFragments, func(f models.Fragment) time.Time { return f.Date })
+
+	s.shelfBySlug = make(map[string]models.ShelfItem, len(s.liveShelf))
+	for _, item := range s.liveShelf {
+		s.shelfBySlug[item.Slug] = item
+	}
+
+	s.pixelIndex = make(map[string]int, len(s.livePixels))
+	for i, p := range s.livePixels {
+		s.pixelIndex[p.Slug] = i
+	}
+
+	s.indexTags()
+	s.indexArchive()
+	s.indexRelated()
+	s.indexSeries()
+	s.search = search.NewIndex(s.searchDocuments())
+}
+
+// searchDocuments flattens every live entry into a searchable document
+func (s *Store) searchDocuments() []search.Document {
+	var docs []search.Document
+	for _, a := range s.liveArticles {
+		docs = append(docs, search.Document{
+			Section: "articles", Slug: a.Slug, Title: a.Title,
+			URL: "/articles/" + a.Slug, Text: a.PlainText, Date: a.Date,
+		})
+	}
+	for _, f := range s.liveFragments {
+		docs = append(docs, search.Document{
+			Section: "fragments", Slug: f.Slug, Title: f.Title,
+			URL: "/fragments/" + f.Slug, Text: f.PlainText, Date: f.Date,
+		})
+	}
+	for _, item := range s.liveShelf {
+		docs = append(docs, search.Document{
+			Section: "shelf", Slug: item.Slug, Title: item.Title,
+			URL: "/shelf/" + item.Slug, Text: item.PlainText, Date: item.Date,
+		})
+	}
+	for _, p := range s.livePixels {
+		docs = append(docs, search.Document{
+			Section: "pixels", Slug: p.Slug, Title: p.Title,
+			URL: "/pixels/" + p.Slug, Text: p.PlainText, Date: p.Date,
+		})
+	}
+	return docs
+}
+
+// live returns the items readers may see at now and records the next
+// scheduled publish time among the ones still hidden
+func live[T publishable](s *Store, items []T, now time.Time) []T {
+	if s.opts.Drafts {
+		return items
+	}
+
+	out := make([]T, 0, len(items))
+	for _, item := range items {
+		if item.IsPublished(now) {
+			out = append(out, item)
+			continue
+		}
+		at := item.PublishTime()
+		if at.After(now) && (s.nextPublish.IsZero() || at.Before(s.nextPublish)) {
+			s.nextPublish = at
+		}
+	}
+	return out
+}
+
+// refresh rebuilds the indexes once a scheduled entry's publish time has
+// passed, so it appears without a restart
+func (s *Store) refresh() {
+	s.mu.RLock()
+	due := !s.nextPublish.IsZero() && !time.Now().Before(s.nextPublish)
+	s.mu.RUnlock()
+	if !due {
+		return
+	}
+
+	s.mu.Lock()
+	if !s.nextPublish.IsZero() && !time.Now().Before(s.nextPublish) {
+		s.index()
+	}
+	s.mu.Unlock()
+}
+
+// Articles returns all published articles, newest first
+func (s *Store) Articles() []models.Article {
+	s.refresh()
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.liveArticles
+}
+
+// Article looks up a single article by slug
+func (s *Store) Article(slug string) (models.Article, bool) {
+	s.refresh()
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	i, ok := s.articleIndex[slug]
+	if !ok {
+		return models.Article{}, false
+	}
+	return s.liveArticles[i], true
+}
+
+// ArticleNeighbours returns the articles published just after and just
+// before the one with slug; either is nil at the ends
+func (s *Store) ArticleNeighbours(slug string) (newer, older *models.Article) {
+	s.refresh()
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	i, ok := s.articleIndex[slug]
+	if !ok {
+		return nil, nil
+	}
+	return neighbours(s.liveArticles, i)
+}
+
+// ArticleTags returns every article tag and how many articles use it
+func (s *Store) ArticleTags() map[string]int {
+	s.refresh()
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.articleTags
+}
+
+// ArticlesByYear returns the years that have articles and the articles
+// published in each, both newest first and from the same snapshot
+func (s *Store) ArticlesByYear() (years []int, byYear map[int][]models.Article) {
+	s.refresh()
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.articleYears, s.articlesByYear
+}
+
+// Fragments returns all published fragments, newest first
+func (s *Store) Fragments() []models.Fragment {
+	s.refresh()
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.liveFragments
+}
+
+// Fragment looks up a single fragment by slug
+func (s *Store) Fragment(slug string) (models.Fragment, bool) {
+	s.refresh()
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	i, ok := s.fragmentIndex[slug]
+	if !ok {
+		return models.Fragment{}, false
+	}
+	return s.liveFragments[i], true
+}
+
+// FragmentNeighbours returns the fragments published just after and just
+// before the one with slug; either is nil at the ends
+func (s *Store) FragmentNeighbours(slug string) (newer, older *models.Fragment) {
+	s.refresh()
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	i, ok := s.fragmentIndex[slug]
+	if !ok {
+		return nil, nil
+	}
+	return neighbours(s.liveFragments, i)
+}
+
+// FragmentsByYear returns the years that have fragments and the fragments
+// published in each, both newest first and from the same snapshot
+func (s *Store) FragmentsByYear() (years []int, byYear map[int][]models.Fragment) {
+	s.refresh()
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.fragmentYears, s.fragmentsByYear
+}
+
+// ShelfItems returns all published shelf items, newest first
+func (s *Store) ShelfItems() []models.ShelfItem {
+	s.refresh()
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.liveShelf
+}
+
+// ShelfItem looks up a single shelf item by slug
+func (s *Store) ShelfItem(slug string) (models.ShelfItem, bool) {
+	s.refresh()
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	item, ok := s.shelfBySlug[slug]
+	return item, ok
+}
+
+// DraftArticle looks up an article by slug whether or not it is published
+func (s *Store) DraftArticle(slug string) (models.Article, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return find(s.articles, func(a models.Article) bool { return a.Slug == slug })
+}
+
+// DraftFragment looks up a fragment by slug whether or not it is published
+func (s *Store) DraftFragment(slug string) (models.Fragment, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return find(s.fragments, func(f models.Fragment) bool { return f.Slug == slug })
+}
+
+// DraftShelfItem looks up a shelf item by slug whether or not it is published
+func (s *Store) DraftShelfItem(slug string) (models.ShelfItem, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return find(s.shelf, func(i models.ShelfItem) bool { return i.Slug == slug })
+}
+
+// find returns the first item matching ok; drafts are rare so a scan is fine
+func find[T any](items []T, ok func(T) bool) (T, bool) {
+	for _, item := range items {
+		if ok(item) {
+			return item, true
+		}
+	}
+	var zero T
+	return zero, false
+}
+
+// Pixels returns all published pixels, newest first
+func (s *Store) Pixels() []models.Pixel {
+	s.refresh()
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.livePixels
+}
+
+// Pixel returns the published pixel with the given slug
+func (s *Store) Pixel(slug string) (models.Pixel, bool) {
+	s.refresh()
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	i, ok := s.pixelIndex[slug]
+	if !ok {
+		return models.Pixel{}, false
+	}
+	return s.livePixels[i], true
+}
+
+// PixelNeighbours returns the pixels published just after and just before
+// the one with slug; either is nil at the ends of the feed
+func (s *Store) PixelNeighbours(slug string) (newer, older *models.Pixel) {
+	s.refresh()
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	i, ok := s.pixelIndex[slug]
+	if !ok {
+		return nil, nil
+	}
+	return neighbours(s.livePixels, i)
+}
+
+// neighbours returns copies of the items either side of items[i] in a
+// newest-first list
+func neighbours[T any](items []T, i int) (newer, older *T) {
+	if i > 0 {
+		n := items[i-1]
+		newer = &n
+	}
+	if i+1 < len(items) {
+		o := items[i+1]
+		older = &o
+	}
+	return newer, older
+}
+
+// Search ranks published content against query, returning at most limit results
+func (s *Store) Search(query string, limit int) []search.Result {
+	s.refresh()
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.search.Search(query, limit)
+}
+
+// About returns the about page
+func (s *Store) About() models.About {
+	s.refresh()
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.about
+}
@@ -0,0 +1,193 @@
+package content
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+
+	"journal/internal/models"
+	"journal/internal/render"
+)
+
+// TagQuery selects entries by tag. Commas separate alternatives (OR) and
+// "+" joins tags that must all be present (AND):
+//
+//	"kafka"                 tagged kafka
+//	"kafka,mysql"           tagged kafka or mysql
+//	"kafka+debugging"       tagged both kafka and debugging
+//	"kafka+debugging,mysql" tagged both kafka and debugging, or mysql
+//
+// Tags that contain a space, "+" or "," are escaped in URLs (see
+// render.EscapeTag), so "system%20design" and "c%2B%2B" are single tags.
+type TagQuery [][]string
+
+// ParseTagQuery parses the tag query syntax shared by /tags/{query} and
+// ?tags=. It takes the query still escaped as in the URL, splits it on the
+// operators and only then unescapes each tag.
+func ParseTagQuery(escaped string) TagQuery {
+	var q TagQuery
+	for _, alt := range strings.Split(escaped, ",") {
+		var all []string
+		for _, term := range strings.FieldsFunc(alt, func(r rune) bool {
+			return r == '+' || r == ' '
+		}) {
+			if tag, err := url.PathUnescape(term); err == nil && strings.TrimSpace(tag) != "" {
+				all = append(all, tag)
+			}
+		}
+		if len(all) > 0 {
+			q = append(q, all)
+		}
+	}
+	return q
+}
+
+// IsEmpty reports whether the query selects nothing in particular
+func (q TagQuery) IsEmpty() bool {
+	return len(q) == 0
+}
+
+// Matches reports whether tags satisfy the query; an empty query matches everything
+func (q TagQuery) Matches(tags []string) bool {
+	if q.IsEmpty() {
+		return true
+	}
+
+	set := make(map[string]bool, len(tags))
+	for _, t := range tags {
+		set[t] = true
+	}
+	for _, all := range q {
+		ok := true
+		for _, t := range all {
+			if !set[t] {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Tags returns every tag mentioned in the query
+func (q TagQuery) Tags() []string {
+	var tags []string
+	for _, all := range q {
+		tags = append(tags, all...)
+	}
+	return tags
+}
+
+// String returns the canonical form, e.g. "kafka+debugging,mysql"
+func (q TagQuery) String() string {
+	alts := make([]string, len(q))
+	for i, all := range q {
+		alts[i] = strings.Join(all, "+")
+	}
+	return strings.Join(alts, ",")
+}
+
+// Escaped returns the canonical form escaped for a URL, as ParseTagQuery reads it
+func (q TagQuery) Escaped() string {
+	alts := make([]string, len(q))
+	for i, all := range q {
+		escaped := make([]string, len(all))
+		for j, tag := range all {
+			escaped[j] = render.EscapeTag(tag)
+		}
+		alts[i] = strings.Join(escaped, "+")
+	}
+	return strings.Join(alts, ",")
+}
+
+// Tagged holds the entries of every section matching a tag query
+type Tagged struct {
+	Articles   []models.Article
+	Fragments  []models.Fragment
+	ShelfItems []models.ShelfItem
+}
+
+// Len returns how many entries matched
+func (t Tagged) Len() int {
+	return len(t.Articles) + len(t.Fragments) + len(t.ShelfItems)
+}
+
+// Tagged returns the published entries of every section matching q, newest first
+func (s *Store) Tagged(q TagQuery) Tagged {
+	s.refresh()
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var t Tagged
+	for _, a := range s.liveArticles {
+		if q.Matches(a.Tags) {
+			t.Articles = append(t.Articles, a)
+		}
+	}
+	for _, f := range s.liveFragments {
+		if q.Matches(f.Tags) {
+			t.Fragments = append(t.Fragments, f)
+		}
+	}
+	for _, item := range s.liveShelf {
+		if q.Matches(item.Tags) {
+			t.ShelfItems = append(t.ShelfItems, item)
+		}
+	}
+	return t
+}
+
+// Tags returns every tag used across articles, fragments and shelf items,
+// sorted by name, with counts and tag-cloud weights
+func (s *Store) Tags() []models.Tag {
+	s.refresh()
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.tags
+}
+
+// indexTags counts tags across sections; called from index
+func (s *Store) indexTags() {
+	counts := make(map[string]int)
+	for _, a := range s.liveArticles {
+		for _, t := range a.Tags {
+			counts[t]++
+		}
+	}
+	for _, f := range s.liveFragments {
+		for _, t := range f.Tags {
+			counts[t]++
+		}
+	}
+	for _, item := range s.liveShelf {
+		for _, t := range item.Tags {
+			counts[t]++
+		}
+	}
+
+	min, max := 0, 0
+	for _, c := range counts {
+		if min == 0 || c < min {
+			min = c
+		}
+		if c > max {
+			max = c
+		}
+	}
+
+	tags := make([]models.Tag, 0, len(counts))
+	for name, c := range counts {
+		weight := 1
+		if max > min {
+			weight = 1 + 4*(c-min)/(max-min)
+		}
+		tags = append(tags, models.Tag{Name: name, Count: c, Weight: weight})
+	}
+	sort.Slice(tags, func(i, j int) bool {
+		return tags[i].Name < tags[j].Name
+	})
+	s.tags = tags
+}
@@ -0,0 +1,65 @@
+package content
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseTagQuery(t *testing.T) {
+	tests := []struct {
+		escaped string
+		want    TagQuery
+	}{
+		{"", nil},
+		{"kafka", TagQuery{{"kafka"}}},
+		{"kafka,mysql", TagQuery{{"kafka"}, {"mysql"}}},
+		{"kafka+debugging", TagQuery{{"kafka", "debugging"}}},
+		{"kafka+debugging,mysql", TagQuery{{"kafka", "debugging"}, {"mysql"}}},
+		{"kafka debugging", TagQuery{{"kafka", "debugging"}}},
+		{",kafka,,+", TagQuery{{"kafka"}}},
+		{"system%20design", TagQuery{{"system design"}}},
+		{"system%20design+go", TagQuery{{"system design", "go"}}},
+		{"c%2B%2B", TagQuery{{"c++"}}},
+		{"c%2B%2B,rust", TagQuery{{"c++"}, {"rust"}}},
+		{"a%2Cb", TagQuery{{"a,b"}}},
+		{"%zz,go", TagQuery{{"go"}}},
+	}
+	for _, tt := range tests {
+		if got := ParseTagQuery(tt.escaped); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseTagQuery(%q) = %q, want %q", tt.escaped, got, tt.want)
+		}
+	}
+}
+
+func TestTagQueryEscapedRoundTrip(t *testing.T) {
+	tests := []TagQuery{
+		{{"kafka"}},
+		{{"system design", "go"}, {"c++"}},
+		{{"a,b"}, {"50%"}},
+	}
+	for _, q := range tests {
+		if got := ParseTagQuery(q.Escaped()); !reflect.DeepEqual(got, q) {
+			t.Errorf("ParseTagQuery(%q) = %q, want %q", q.Escaped(), got, q)
+		}
+	}
+}
+
+func TestTagQueryMatches(t *testing.T) {
+	tags := []string{"system design", "c++", "go"}
+	tests := []struct {
+		escaped string
+		want    bool
+	}{
+		{"", true},
+		{"system%20design", true},
+		{"system+design", false},
+		{"c%2B%2B+go", true},
+		{"rust,go", true},
+		{"rust+go", false},
+	}
+	for _, tt := range tests {
+		if got := ParseTagQuery(tt.escaped).Matches(tags); got != tt.want {
+			t.Errorf("ParseTagQuery(%q).Matches(%q) = %v, want %v", tt.escaped, tags, got, tt.want)
+		}
+	}
+}
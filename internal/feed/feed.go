@@ -0,0 +1,301 @@
+package feed
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"io"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Feed is the format-neutral description of a syndication feed
+type Feed struct {
+	Title       string
+	Description string
+	Link        string // site page the feed belongs to
+	FeedURL     string // absolute URL of the feed itself
+	Author      string
+	Updated     time.Time
+	Items       []Item
+}
+
+// Item is a single entry; Content is full HTML with absolute URLs
+type Item struct {
+	ID        string
+	Title     string
+	URL       string
+	Summary   string
+	Content   string
+	Image     string
+	Tags      []string
+	Published time.Time
+	Updated   time.Time
+
+	// WordCount and ReadingTime (minutes) are left out when zero
+	WordCount   int
+	ReadingTime int
+}
+
+// description is the item summary followed by its reading time and revision
+// date, for formats without a field of their own for them
+func (it Item) description() string {
+	var notes []string
+	if it.ReadingTime > 0 {
+		notes = append(notes, strconv.Itoa(it.ReadingTime)+" min read")
+	}
+	if it.Updated.After(it.Published) {
+		notes = append(notes, "updated on "+it.Updated.Format("Jan 2, 2006"))
+	}
+	switch {
+	case len(notes) == 0:
+		return it.Summary
+	case it.Summary == "":
+		return strings.Join(notes, ", ")
+	}
+	return it.Summary + " (" + strings.Join(notes, ", ") + ")"
+}
+
+// WriteRSS writes f as RSS 2.0
+func WriteRSS(w io.Writer, f Feed) error {
+	type guid struct {
+		IsPermaLink bool   `xml:"isPermaLink,attr"`
+		Value       string `xml:",chardata"`
+	}
+	type item struct {
+		Title       string   `xml:"title"`
+		Link        string   `xml:"link"`
+		GUID        guid     `xml:"guid"`
+		PubDate     string   `xml:"pubDate"`
+		Description string   `xml:"description,omitempty"`
+		Categories  []string `xml:"category"`
+		Content     cdata    `xml:"content:encoded"`
+	}
+	type atomLink struct {
+		Href string `xml:"href,attr"`
+		Rel  string `xml:"rel,attr"`
+		Type string `xml:"type,attr"`
+	}
+	type channel struct {
+		Title         string   `xml:"title"`
+		Link          string   `xml:"link"`
+		Description   string   `xml:"description"`
+		AtomLink      atomLink `xml:"atom:link"`
+		LastBuildDate string   `xml:"lastBuildDate"`
+		Items         []item   `xml:"item"`
+	}
+	type rss struct {
+		XMLName   xml.Name `xml:"rss"`
+		Version   string   `xml:"version,attr"`
+		ContentNS string   `xml:"xmlns:content,attr"`
+		AtomNS    string   `xml:"xmlns:atom,attr"`
+		Channel   channel  `xml:"channel"`
+	}
+
+	doc := rss{
+		Version:   "2.0",
+		ContentNS: "http://purl.org/rss/1.0/modules/content/",
+		AtomNS:    "http://www.w3.org/2005/Atom",
+		Channel: channel{
+			Title:         f.Title,
+			Link:          f.Link,
+			Description:   f.Description,
+			AtomLink:      atomLink{Href: f.FeedURL, Rel: "self", Type: "application/rss+xml"},
+			LastBuildDate: f.Updated.Format(time.RFC1123Z),
+		},
+	}
+	for _, it := range f.Items {
+		doc.Channel.Items = append(doc.Channel.Items, item{
+			Title:       it.Title,
+			Link:        it.URL,
+			GUID:        guid{IsPermaLink: it.ID == it.URL, Value: it.ID},
+			PubDate:     it.Published.Format(time.RFC1123Z),
+			Description: it.description(),
+			Categories:  it.Tags,
+			Content:     cdata{it.Content},
+		})
+	}
+
+	return writeXML(w, doc)
+}
+
+// WriteAtom writes f as an Atom 1.0 feed
+func WriteAtom(w io.Writer, f Feed) error {
+	type link struct {
+		Href string `xml:"href,attr"`
+		Rel  string `xml:"rel,attr,omitempty"`
+		Type string `xml:"type,attr,omitempty"`
+	}
+	type text struct {
+		Type  string `xml:"type,attr,omitempty"`
+		Value string `xml:",chardata"`
+	}
+	type category struct {
+		Term string `xml:"term,attr"`
+	}
+	type person struct {
+		Name string `xml:"name"`
+	}
+	type entry struct {
+		ID         string     `xml:"id"`
+		Title      string     `xml:"title"`
+		Link       link       `xml:"link"`
+		Published  string     `xml:"published"`
+		Updated    string     `xml:"updated"`
+		Summary    *text      `xml:"summary,omitempty"`
+		Content    text       `xml:"content"`
+		Categories []category `xml:"category"`
+	}
+	type atom struct {
+		XMLName  xml.Name `xml:"http://www.w3.org/2005/Atom feed"`
+		ID       string   `xml:"id"`
+		Title    string   `xml:"title"`
+		Subtitle string   `xml:"subtitle,omitempty"`
+		Updated  string   `xml:"updated"`
+		Author   person   `xml:"author"`
+		Links    []link   `xml:"link"`
+		Entries  []entry  `xml:"entry"`
+	}
+
+	doc := atom{
+		ID:       f.FeedURL,
+		Title:    f.Title,
+		Subtitle: f.Description,
+		Updated:  f.Updated.Format(time.RFC3339),
+		Author:   person{Name: f.Author},
+		Links: []link{
+			{Href: f.FeedURL, Rel: "self", Type: "application/atom+xml"},
+			{Href: f.Link, Rel: "alternate", Type: "text/html"},
+		},
+	}
+	for _, it := range f.Items {
+		e := entry{
+			ID:        it.ID,
+			Title:     it.Title,
+			Link:      link{Href: it.URL, Rel: "alternate", Type: "text/html"},
+			Published: it.Published.Format(time.RFC3339),
+			Updated:   it.Updated.Format(time.RFC3339),
+			Content:   text{Type: "html", Value: it.Content},
+		}
+		if summary := it.description(); summary != "" {
+			e.Summary = &text{Type: "text", Value: summary}
+		}
+		for _, tag := range it.Tags {
+			e.Categories = append(e.Categories, category{Term: tag})
+		}
+		doc.Entries = append(doc.Entries, e)
+	}
+
+	return writeXML(w, doc)
+}
+
+// WriteJSON writes f as JSON Feed 1.1
+func WriteJSON(w io.Writer, f Feed) error {
+	type author struct {
+		Name string `json:"name"`
+	}
+	// reading is a JSON Feed extension; readers ignore unknown _ keys
+	type reading struct {
+		WordCount   int `json:"word_count"`
+		ReadingTime int `json:"reading_time_minutes"`
+	}
+	type item struct {
+		ID            string   `json:"id"`
+		URL           string   `json:"url"`
+		Title         string   `json:"title"`
+		ContentHTML   string   `json:"content_html"`
+		Summary       string   `json:"summary,omitempty"`
+		Image         string   `json:"image,omitempty"`
+		DatePublished string   `json:"date_published"`
+		DateModified  string   `json:"date_modified"`
+		Tags          []string `json:"tags,omitempty"`
+		Reading       *reading `json:"_reading,omitempty"`
+	}
+	type jsonFeed struct {
+		Version     string   `json:"version"`
+		Title       string   `json:"title"`
+		HomePageURL string   `json:"home_page_url"`
+		FeedURL     string   `json:"feed_url"`
+		Description string   `json:"description,omitempty"`
+		Authors     []author `json:"authors,omitempty"`
+		Items       []item   `json:"items"`
+	}
+
+	doc := jsonFeed{
+		Version:     "https://jsonfeed.org/version/1.1",
+		Title:       f.Title,
+		HomePageURL: f.Link,
+		FeedURL:     f.FeedURL,
+		Description: f.Description,
+		Items:       []item{},
+	}
+	if f.Author != "" {
+		doc.Authors = []author{{Name: f.Author}}
+	}
+	for _, it := range f.Items {
+		var r *reading
+		if it.WordCount > 0 || it.ReadingTime > 0 {
+			r = &reading{WordCount: it.WordCount, ReadingTime: it.ReadingTime}
+		}
+		doc.Items = append(doc.Items, item{
+			ID:            it.ID,
+			URL:           it.URL,
+			Title:         it.Title,
+			ContentHTML:   it.Content,
+			Summary:       it.Summary,
+			Image:         it.Image,
+			DatePublished: it.Published.Format(time.RFC3339),
+			DateModified:  it.Updated.Format(time.RFC3339),
+			Tags:          it.Tags,
+			Reading:       r,
+		})
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(doc)
+}
+
+// cdata wraps HTML so feed readers get it unescaped
+type cdata struct {
+	Value string `xml:",cdata"`
+}
+
+func writeXML(w io.Writer, doc any) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+var urlAttr = regexp.MustCompile(`(?i)\b(href|src)="([^"]*)"`)
+
+// AbsoluteURLs rewrites relative href and src attributes in html against base,
+// so links and images keep working inside feed readers
+func AbsoluteURLs(html, base string) string {
+	baseURL, err := url.Parse(base)
+	if err != nil {
+		return html
+	}
+
+	return urlAttr.ReplaceAllStringFunc(html, func(attr string) string {
+		m := urlAttr.FindStringSubmatch(attr)
+		ref := m[2]
+		if ref == "" || strings.HasPrefix(ref, "data:") || strings.HasPrefix(ref, "mailto:") {
+			return attr
+		}
+		u, err := url.Parse(ref)
+		if err != nil || u.IsAbs() {
+			return attr
+		}
+		return m[1] + `="` + baseURL.ResolveReference(u).String() + `"`
+	})
+}
@@ -0,0 +1,386 @@
+// Package gitlog reads commit history straight from a repository's .git
+// directory, without shelling out to git, to find when files were first
+// and last changed.
+package gitlog
+
+import (
+	"bytes"
+	"container/heap"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrNoRepo is returned by Open when path is not inside a git repository
+var ErrNoRepo = errors.New("not a git repository")
+
+// Repo is a git repository opened for reading. It is not safe for
+// concurrent use.
+type Repo struct {
+	root      string // working tree
+	gitDir    string // HEAD lives here
+	commonDir string // objects and refs live here; differs for worktrees
+	packs     []*pack
+	trees     map[hash]map[string]hash
+}
+
+// Dates records the commits that first added and last changed a file
+type Dates struct {
+	Created  time.Time
+	Modified time.Time
+
+	// Commits counts the commits that touched the file
+	Commits int
+}
+
+// Open finds the repository containing path by walking up to the nearest
+// .git directory or file
+func Open(path string) (*Repo, error) {
+	dir, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		dotGit := filepath.Join(dir, ".git")
+		info, err := os.Stat(dotGit)
+		if err == nil {
+			gitDir := dotGit
+			if !info.IsDir() {
+				// Worktrees and submodules point at their git dir from a file
+				if gitDir, err = readGitFile(dotGit); err != nil {
+					return nil, err
+				}
+			}
+			return openGitDir(dir, gitDir)
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return nil, ErrNoRepo
+		}
+		dir = parent
+	}
+}
+
+// readGitFile resolves a .git file of the form "gitdir: <path>"
+func readGitFile(file string) (string, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return "", err
+	}
+	dir, ok := strings.CutPrefix(strings.TrimSpace(string(data)), "gitdir: ")
+	if !ok {
+		return "", fmt.Errorf("%s: malformed .git file", file)
+	}
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(filepath.Dir(file), dir)
+	}
+	return dir, nil
+}
+
+func openGitDir(root, gitDir string) (*Repo, error) {
+	r := &Repo{
+		root:      root,
+		gitDir:    gitDir,
+		commonDir: gitDir,
+		trees:     make(map[hash]map[string]hash),
+	}
+	if data, err := os.ReadFile(filepath.Join(gitDir, "commondir")); err == nil {
+		common := strings.TrimSpace(string(data))
+		if !filepath.IsAbs(common) {
+			common = filepath.Join(gitDir, common)
+		}
+		r.commonDir = common
+	}
+
+	// Only SHA-1 object ids are understood
+	if config, err := os.ReadFile(filepath.Join(r.commonDir, "config")); err == nil {
+		if bytes.Contains(config, []byte("objectformat = sha256")) {
+			return nil, errors.New("sha256 repositories are not supported")
+		}
+	}
+
+	if err := r.loadPacks(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Root returns the absolute path of the working tree
+func (r *Repo) Root() string {
+	return r.root
+}
+
+// Head returns the id of the commit checked out, or "" on an unborn branch
+func (r *Repo) Head() (string, error) {
+	h, err := r.head()
+	if errors.Is(err, errNotFound) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return h.String(), nil
+}
+
+func (r *Repo) head() (hash, error) {
+	data, err := os.ReadFile(filepath.Join(r.gitDir, "HEAD"))
+	if err != nil {
+		return hash{}, err
+	}
+	line := strings.TrimSpace(string(data))
+	if ref, ok := strings.CutPrefix(line, "ref: "); ok {
+		return r.resolveRef(ref)
+	}
+	return parseHash(line)
+}
+
+// resolveRef reads a ref from its loose file or, failing that, packed-refs
+func (r *Repo) resolveRef(ref string) (hash, error) {
+	// Symbolic refs may nest; git caps the depth at five
+	for range 5 {
+		data, err := os.ReadFile(filepath.Join(r.commonDir, filepath.FromSlash(ref)))
+		if err != nil {
+			break
+		}
+		line := strings.TrimSpace(string(data))
+		next, ok := strings.CutPrefix(line, "ref: ")
+		if !ok {
+			return parseHash(line)
+		}
+		ref = next
+	}
+
+	data, err := os.ReadFile(filepath.Join(r.commonDir, "packed-refs"))
+	if err != nil && !os.IsNotExist(err) {
+		return hash{}, err
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		id, name, ok := strings.Cut(line, " ")
+		if ok && name == ref {
+			return parseHash(id)
+		}
+	}
+	return hash{}, fmt.Errorf("%s: %w", ref, errNotFound)
+}
+
+// commit is the part of a commit object the walk needs
+type commit struct {
+	id        hash
+	tree      hash
+	parents   []hash
+	author    time.Time
+	committer time.Time
+}
+
+func (r *Repo) readCommit(id hash) (*commit, error) {
+	kind, data, err := r.readObject(id)
+	if err != nil {
+		return nil, err
+	}
+	if kind != objCommit {
+		return nil, fmt.Errorf("%s: not a commit", id)
+	}
+
+	c := &commit{id: id}
+	// Headers end at the first blank line; the message follows
+	for _, line := range strings.Split(string(data), "\n") {
+		if line == "" {
+			break
+		}
+		key, value, _ := strings.Cut(line, " ")
+		switch key {
+		case "tree":
+			if c.tree, err = parseHash(value); err != nil {
+				return nil, err
+			}
+		case "parent":
+			p, err := parseHash(value)
+			if err != nil {
+				return nil, err
+			}
+			c.parents = append(c.parents, p)
+		case "author":
+			c.author = signatureTime(value)
+		case "committer":
+			c.committer = signatureTime(value)
+		}
+	}
+	return c, nil
+}
+
+// signatureTime parses the timestamp of "Name <email> 1700000000 +0100"
+func signatureTime(sig string) time.Time {
+	fields := strings.Fields(sig[strings.LastIndexByte(sig, '>')+1:])
+	if len(fields) != 2 {
+		return time.Time{}
+	}
+	secs, err := strconv.ParseInt(fields[0], 10, 64)
+	if err != nil {
+		return time.Time{}
+	}
+	t := time.Unix(secs, 0)
+	if zone, err := time.Parse("-0700", fields[1]); err == nil {
+		t = t.In(zone.Location())
+	}
+	return t
+}
+
+// readTree returns the entries of a tree by name, caching parsed trees
+// since most of them are shared between neighbouring commits
+func (r *Repo) readTree(id hash) (map[string]hash, error) {
+	if entries, ok := r.trees[id]; ok {
+		return entries, nil
+	}
+	kind, data, err := r.readObject(id)
+	if err != nil {
+		return nil, err
+	}
+	if kind != objTree {
+		return nil, fmt.Errorf("%s: not a tree", id)
+	}
+
+	// Each entry is "<mode> <name>\0" followed by a raw 20-byte id
+	entries := make(map[string]hash)
+	for len(data) > 0 {
+		header, rest, ok := bytes.Cut(data, []byte{0})
+		if !ok || len(rest) < 20 {
+			return nil, fmt.Errorf("%s: malformed tree", id)
+		}
+		_, name, _ := bytes.Cut(header, []byte{' '})
+		var h hash
+		copy(h[:], rest[:20])
+		entries[string(name)] = h
+		data = rest[20:]
+	}
+	r.trees[id] = entries
+	return entries, nil
+}
+
+// lookup returns the id of the object at a slash-separated path in tree,
+// or the zero hash if there is none
+func (r *Repo) lookup(tree hash, path string) (hash, error) {
+	for {
+		entries, err := r.readTree(tree)
+		if err != nil {
+			return hash{}, err
+		}
+		name, rest, nested := strings.Cut(path, "/")
+		id, ok := entries[name]
+		if !ok {
+			return hash{}, nil
+		}
+		if !nested {
+			return id, nil
+		}
+		tree, path = id, rest
+	}
+}
+
+// History walks every commit reachable from HEAD and reports, for each of
+// paths (slash-separated and relative to Root), the commits that first
+// added and last changed it. Like git log, a merge only counts as touching
+// a file when it differs from every parent. Paths never committed are left
+// out of the result; commits missing from a shallow clone end the walk.
+func (r *Repo) History(paths []string) (map[string]Dates, error) {
+	result := make(map[string]Dates)
+	start, err := r.head()
+	if errors.Is(err, errNotFound) {
+		return result, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	first, err := r.readCommit(start)
+	if err != nil {
+		return nil, err
+	}
+	queue := &commitQueue{first}
+	seen := map[hash]bool{start: true}
+
+	for queue.Len() > 0 {
+		c := heap.Pop(queue).(*commit)
+
+		var parents []*commit
+		for _, id := range c.parents {
+			p, err := r.readCommit(id)
+			if errors.Is(err, errNotFound) {
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			parents = append(parents, p)
+			if !seen[id] {
+				seen[id] = true
+				heap.Push(queue, p)
+			}
+		}
+
+		for _, path := range paths {
+			touched, err := r.touches(c, parents, path)
+			if err != nil {
+				return nil, err
+			}
+			if !touched {
+				continue
+			}
+
+			// Commits come newest first, so the first touch is the last
+			// change and every later one moves the creation back
+			d := result[path]
+			if d.Commits == 0 {
+				d.Modified = c.author
+			}
+			d.Created = c.author
+			d.Commits++
+			result[path] = d
+		}
+	}
+	return result, nil
+}
+
+// touches reports whether c changed path relative to all of its parents
+func (r *Repo) touches(c *commit, parents []*commit, path string) (bool, error) {
+	blob, err := r.lookup(c.tree, path)
+	if err != nil {
+		return false, err
+	}
+	if len(parents) == 0 {
+		return blob != hash{}, nil
+	}
+	for _, p := range parents {
+		if p.tree == c.tree {
+			return false, nil
+		}
+		before, err := r.lookup(p.tree, path)
+		if err != nil {
+			return false, err
+		}
+		if before == blob {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// commitQueue orders commits newest first by committer time
+type commitQueue []*commit
+
+func (q commitQueue) Len() int           { return len(q) }
+func (q commitQueue) Less(i, j int) bool { return q[i].committer.After(q[j].committer) }
+func (q commitQueue) Swap(i, j int)      { q[i], q[j] = q[j], q[i] }
+func (q *commitQueue) Push(x any)        { *q = append(*q, x.(*commit)) }
+
+func (q *commitQueue) Pop() any {
+	old := *q
+	c := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return c
+}
@@ -0,0 +1,207 @@
+package gitlog
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testRepo builds a repository in a temporary directory with the git CLI,
+// committing on whole days so each commit's dates are known
+type testRepo struct {
+	t   *testing.T
+	dir string
+}
+
+func newTestRepo(t *testing.T) *testRepo {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	r := &testRepo{t: t, dir: t.TempDir()}
+	r.git(0, "init", "-q")
+	r.git(0, "checkout", "-q", "-b", "main")
+	return r
+}
+
+// day is the time of the commits made on day n
+func day(n int) time.Time {
+	return time.Date(2024, time.January, n, 12, 0, 0, 0, time.UTC)
+}
+
+// git runs a git command dated day n and fails the test if it fails
+func (r *testRepo) git(n int, args ...string) string {
+	r.t.Helper()
+	out, err := r.run(n, args...)
+	if err != nil {
+		r.t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, out)
+	}
+	return out
+}
+
+// run runs a git command dated day n
+func (r *testRepo) run(n int, args ...string) (string, error) {
+	date := day(max(n, 1)).Format(time.RFC3339)
+	cmd := exec.Command("git", args...)
+	cmd.Dir = r.dir
+	cmd.Env = append(os.Environ(),
+		"GIT_CONFIG_GLOBAL=/dev/null", "GIT_CONFIG_NOSYSTEM=1",
+		"GIT_AUTHOR_NAME=Test", "GIT_AUTHOR_EMAIL=test@example.com", "GIT_AUTHOR_DATE="+date,
+		"GIT_COMMITTER_NAME=Test", "GIT_COMMITTER_EMAIL=test@example.com", "GIT_COMMITTER_DATE="+date,
+	)
+	out, err := cmd.CombinedOutput()
+	return string(out), err
+}
+
+// commit writes files, relative to the working tree, and commits them on day n
+func (r *testRepo) commit(n int, files map[string]string) {
+	r.t.Helper()
+	for name, data := range files {
+		file := filepath.Join(r.dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+			r.t.Fatal(err)
+		}
+		if err := os.WriteFile(file, []byte(data), 0o644); err != nil {
+			r.t.Fatal(err)
+		}
+	}
+	r.git(n, "add", "-A")
+	r.git(n, "commit", "-q", "-m", fmt.Sprintf("day %d", n))
+}
+
+// long returns a file big enough for git to store revisions of it as deltas
+func long(last string) string {
+	return strings.Repeat("a line that stays the same in every revision\n", 200) + last + "\n"
+}
+
+// buildHistory commits the history TestHistory expects: a.md created and
+// changed on main, b.md changed only on a side branch that is merged back,
+// c.md added on main while the branch was open
+func buildHistory(r *testRepo) {
+	r.commit(1, map[string]string{"content/a.md": long("one"), "content/sub/b.md": "one"})
+	r.commit(2, map[string]string{"content/a.md": long("two")})
+	r.git(3, "checkout", "-q", "-b", "side")
+	r.commit(3, map[string]string{"content/sub/b.md": "two"})
+	r.git(4, "checkout", "-q", "main")
+	r.commit(4, map[string]string{"content/c.md": "one", "other.txt": "x"})
+	r.git(5, "merge", "-q", "--no-ff", "-m", "merge side", "side")
+}
+
+func TestHistory(t *testing.T) {
+	tests := []struct {
+		name string
+		pack func(r *testRepo)
+	}{
+		{"loose", func(r *testRepo) {}},
+		{"packed", func(r *testRepo) {
+			r.git(6, "repack", "-a", "-d", "-q", "-f", "--depth=50", "--window=50")
+			r.git(6, "prune-packed")
+			r.git(6, "pack-refs", "--all")
+		}},
+	}
+	want := map[string]Dates{
+		"content/a.md":     {Created: day(1), Modified: day(2), Commits: 2},
+		"content/sub/b.md": {Created: day(1), Modified: day(3), Commits: 2},
+		"content/c.md":     {Created: day(4), Modified: day(4), Commits: 1},
+	}
+	paths := []string{"content/a.md", "content/sub/b.md", "content/c.md", "content/missing.md"}
+
+	for _, tt := range tests {
+		r := newTestRepo(t)
+		buildHistory(r)
+		tt.pack(r)
+		if tt.name == "packed" {
+			loose, _ := filepath.Glob(filepath.Join(r.dir, ".git", "objects", "??", "*"))
+			if len(loose) > 0 {
+				t.Fatalf("%s: %d objects left loose after repacking", tt.name, len(loose))
+			}
+		}
+
+		repo, err := Open(filepath.Join(r.dir, "content"))
+		if err != nil {
+			t.Fatalf("%s: Open: %v", tt.name, err)
+		}
+		got, err := repo.History(paths)
+		if err != nil {
+			t.Fatalf("%s: History: %v", tt.name, err)
+		}
+		for path, w := range want {
+			d, ok := got[path]
+			if !ok {
+				t.Errorf("%s: no history for %s", tt.name, path)
+				continue
+			}
+			if !d.Created.Equal(w.Created) || !d.Modified.Equal(w.Modified) || d.Commits != w.Commits {
+				t.Errorf("%s: %s = created %v, modified %v, %d commits; want %v, %v, %d",
+					tt.name, path, d.Created, d.Modified, d.Commits, w.Created, w.Modified, w.Commits)
+			}
+		}
+		if d, ok := got["content/missing.md"]; ok {
+			t.Errorf("%s: history for a path never committed: %+v", tt.name, d)
+		}
+	}
+}
+
+func TestHistoryMergeChange(t *testing.T) {
+	// A merge that resolves a conflict differs from both parents, so it
+	// counts as a change of its own
+	r := newTestRepo(t)
+	r.commit(1, map[string]string{"a.md": "one"})
+	r.git(2, "checkout", "-q", "-b", "side")
+	r.commit(2, map[string]string{"a.md": "side"})
+	r.git(3, "checkout", "-q", "main")
+	r.commit(3, map[string]string{"a.md": "main"})
+	if _, err := r.run(4, "merge", "-q", "side"); err == nil {
+		t.Fatal("merge did not conflict")
+	}
+	r.commit(4, map[string]string{"a.md": "resolved"})
+
+	repo, err := Open(r.dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := repo.History([]string{"a.md"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Dates{Created: day(1), Modified: day(4), Commits: 4}
+	if d := got["a.md"]; !d.Created.Equal(want.Created) || !d.Modified.Equal(want.Modified) || d.Commits != want.Commits {
+		t.Errorf("a.md = %+v, want %+v", d, want)
+	}
+}
+
+func TestHistoryUnborn(t *testing.T) {
+	r := newTestRepo(t)
+	repo, err := Open(r.dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if head, err := repo.Head(); err != nil || head != "" {
+		t.Errorf("Head() = %q, %v; want an unborn branch", head, err)
+	}
+	got, err := repo.History([]string{"a.md"})
+	if err != nil || len(got) != 0 {
+		t.Errorf("History() = %v, %v; want nothing", got, err)
+	}
+}
+
+func TestHead(t *testing.T) {
+	r := newTestRepo(t)
+	r.commit(1, map[string]string{"a.md": "one"})
+	want := strings.TrimSpace(r.git(1, "rev-parse", "HEAD"))
+
+	repo, err := Open(r.dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := repo.Head(); err != nil || got != want {
+		t.Errorf("Head() = %q, %v; want %q", got, err, want)
+	}
+	if got := repo.Root(); got != r.dir {
+		t.Errorf("Root() = %q, want %q", got, r.dir)
+	}
+}
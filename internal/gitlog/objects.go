@@ -0,0 +1,318 @@
+package gitlog
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Object types as numbered in pack files
+const (
+	objCommit   = 1
+	objTree     = 2
+	objBlob     = 3
+	objTag      = 4
+	objOfsDelta = 6
+	objRefDelta = 7
+)
+
+var errNotFound = errors.New("object not found")
+
+// hash is a SHA-1 object id
+type hash [20]byte
+
+func parseHash(s string) (hash, error) {
+	var h hash
+	if len(s) != 40 {
+		return h, fmt.Errorf("bad object id %q", s)
+	}
+	_, err := hex.Decode(h[:], []byte(s))
+	return h, err
+}
+
+func (h hash) String() string {
+	return hex.EncodeToString(h[:])
+}
+
+// pack is one packfile with its version 2 index loaded into memory; the
+// pack itself is read on first use
+type pack struct {
+	path    string
+	hashes  []byte // sorted object ids, 20 bytes each
+	offsets []uint64
+	data    []byte
+}
+
+// readObject returns the type and inflated content of the object with id h,
+// looking in loose objects first and then in every pack
+func (r *Repo) readObject(h hash) (int, []byte, error) {
+	name := h.String()
+	if f, err := os.Open(filepath.Join(r.commonDir, "objects", name[:2], name[2:])); err == nil {
+		defer f.Close()
+		return readLoose(f)
+	}
+
+	for _, p := range r.packs {
+		if off, ok := p.find(h); ok {
+			return r.readPacked(p, off)
+		}
+	}
+	return 0, nil, fmt.Errorf("%s: %w", name, errNotFound)
+}
+
+// readLoose inflates a loose object: "<type> <size>\0<content>"
+func readLoose(f io.Reader) (int, []byte, error) {
+	zr, err := zlib.NewReader(f)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer zr.Close()
+	data, err := io.ReadAll(zr)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	header, content, ok := bytes.Cut(data, []byte{0})
+	if !ok {
+		return 0, nil, errors.New("malformed loose object")
+	}
+	kind, _, _ := strings.Cut(string(header), " ")
+	switch kind {
+	case "commit":
+		return objCommit, content, nil
+	case "tree":
+		return objTree, content, nil
+	case "blob":
+		return objBlob, content, nil
+	case "tag":
+		return objTag, content, nil
+	}
+	return 0, nil, fmt.Errorf("unknown object type %q", kind)
+}
+
+// loadPacks reads the index of every pack under objects/pack
+func (r *Repo) loadPacks() error {
+	idxFiles, err := filepath.Glob(filepath.Join(r.commonDir, "objects", "pack", "*.idx"))
+	if err != nil {
+		return err
+	}
+	for _, idx := range idxFiles {
+		p, err := readIndex(idx)
+		if err != nil {
+			return fmt.Errorf("%s: %w", idx, err)
+		}
+		r.packs = append(r.packs, p)
+	}
+	return nil
+}
+
+// readIndex parses a version 2 pack index: header, fan-out table, sorted
+// ids, CRCs, 4-byte offsets and then 8-byte offsets for large packs
+func readIndex(path string) (*pack, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < 8+256*4 || !bytes.Equal(data[:4], []byte{0xff, 't', 'O', 'c'}) || binary.BigEndian.Uint32(data[4:]) != 2 {
+		return nil, errors.New("unsupported pack index version")
+	}
+
+	n := int(binary.BigEndian.Uint32(data[8+255*4:]))
+	hashesAt := 8 + 256*4
+	offsetsAt := hashesAt + n*20 + n*4
+	largeAt := offsetsAt + n*4
+	if len(data) < largeAt {
+		return nil, errors.New("truncated pack index")
+	}
+
+	p := &pack{
+		path:    strings.TrimSuffix(path, ".idx") + ".pack",
+		hashes:  data[hashesAt : hashesAt+n*20],
+		offsets: make([]uint64, n),
+	}
+	for i := range n {
+		off := binary.BigEndian.Uint32(data[offsetsAt+i*4:])
+		if off&0x80000000 == 0 {
+			p.offsets[i] = uint64(off)
+			continue
+		}
+		at := largeAt + int(off&0x7fffffff)*8
+		if len(data) < at+8 {
+			return nil, errors.New("truncated pack index")
+		}
+		p.offsets[i] = binary.BigEndian.Uint64(data[at:])
+	}
+	return p, nil
+}
+
+// find returns the offset of h in the pack
+func (p *pack) find(h hash) (uint64, bool) {
+	n := len(p.offsets)
+	i := sort.Search(n, func(i int) bool {
+		return bytes.Compare(p.hashes[i*20:i*20+20], h[:]) >= 0
+	})
+	if i < n && bytes.Equal(p.hashes[i*20:i*20+20], h[:]) {
+		return p.offsets[i], true
+	}
+	return 0, false
+}
+
+// readPacked reads the object at off in p, resolving delta chains
+func (r *Repo) readPacked(p *pack, off uint64) (int, []byte, error) {
+	if p.data == nil {
+		data, err := os.ReadFile(p.path)
+		if err != nil {
+			return 0, nil, err
+		}
+		p.data = data
+	}
+	if off >= uint64(len(p.data)) {
+		return 0, nil, errors.New("pack offset out of range")
+	}
+	br := bytes.NewReader(p.data[off:])
+
+	// Header: type in bits 4-6 of the first byte, then the size as a
+	// varint we skip since zlib knows where the data ends
+	c, err := br.ReadByte()
+	if err != nil {
+		return 0, nil, err
+	}
+	kind := int(c>>4) & 7
+	for c&0x80 != 0 {
+		if c, err = br.ReadByte(); err != nil {
+			return 0, nil, err
+		}
+	}
+
+	var baseType int
+	var base []byte
+	switch kind {
+	case objOfsDelta:
+		// Distance back to the base, in git's "add one per continuation" varint
+		c, err := br.ReadByte()
+		if err != nil {
+			return 0, nil, err
+		}
+		back := uint64(c & 0x7f)
+		for c&0x80 != 0 {
+			if c, err = br.ReadByte(); err != nil {
+				return 0, nil, err
+			}
+			back = (back+1)<<7 | uint64(c&0x7f)
+		}
+		if back == 0 || back > off {
+			return 0, nil, errors.New("bad delta offset")
+		}
+		baseType, base, err = r.readPacked(p, off-back)
+		if err != nil {
+			return 0, nil, err
+		}
+	case objRefDelta:
+		var h hash
+		if _, err := io.ReadFull(br, h[:]); err != nil {
+			return 0, nil, err
+		}
+		baseType, base, err = r.readObject(h)
+		if err != nil {
+			return 0, nil, err
+		}
+	}
+
+	zr, err := zlib.NewReader(br)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer zr.Close()
+	data, err := io.ReadAll(zr)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	if base == nil {
+		return kind, data, nil
+	}
+	out, err := applyDelta(base, data)
+	return baseType, out, err
+}
+
+// applyDelta rebuilds an object from its base and a delta: two size
+// varints, then copy-from-base and insert-literal instructions
+func applyDelta(base, delta []byte) ([]byte, error) {
+	varint := func() (int, error) {
+		var n, shift int
+		for {
+			if len(delta) == 0 {
+				return 0, errors.New("truncated delta")
+			}
+			c := delta[0]
+			delta = delta[1:]
+			n |= int(c&0x7f) << shift
+			shift += 7
+			if c&0x80 == 0 {
+				return n, nil
+			}
+		}
+	}
+	if _, err := varint(); err != nil { // base size
+		return nil, err
+	}
+	size, err := varint()
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]byte, 0, size)
+	for len(delta) > 0 {
+		cmd := delta[0]
+		delta = delta[1:]
+		switch {
+		case cmd&0x80 != 0:
+			var offset, n int
+			for i := range 4 {
+				if cmd&(1<<i) != 0 {
+					if len(delta) == 0 {
+						return nil, errors.New("truncated delta")
+					}
+					offset |= int(delta[0]) << (8 * i)
+					delta = delta[1:]
+				}
+			}
+			for i := range 3 {
+				if cmd&(0x10<<i) != 0 {
+					if len(delta) == 0 {
+						return nil, errors.New("truncated delta")
+					}
+					n |= int(delta[0]) << (8 * i)
+					delta = delta[1:]
+				}
+			}
+			if n == 0 {
+				n = 0x10000
+			}
+			if offset+n > len(base) {
+				return nil, errors.New("delta copies past end of base")
+			}
+			out = append(out, base[offset:offset+n]...)
+		case cmd != 0:
+			if int(cmd) > len(delta) {
+				return nil, errors.New("truncated delta")
+			}
+			out = append(out, delta[:cmd]...)
+			delta = delta[cmd:]
+		default:
+			return nil, errors.New("reserved delta instruction")
+		}
+	}
+	if len(out) != size {
+		return nil, errors.New("delta size mismatch")
+	}
+	return out, nil
+}
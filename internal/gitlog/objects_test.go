@@ -0,0 +1,60 @@
+package gitlog
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestApplyDelta(t *testing.T) {
+	hello := []byte("hello, world")
+	big := bytes.Repeat([]byte("0123456789abcdef"), 0x1000) // 64 KiB
+	tests := []struct {
+		name  string
+		base  []byte
+		delta []byte
+		want  []byte
+	}{
+		{"copy all", hello, []byte{12, 12, 0x90, 12}, hello},
+		{"insert only", hello, []byte{12, 3, 0x03, 'a', 'b', 'c'}, []byte("abc")},
+		{"copy then insert", hello, []byte{12, 6, 0x91, 7, 5, 0x01, '!'}, []byte("world!")},
+		{"insert then copy", hello, []byte{12, 7, 0x02, 'o', 'h', 0x90, 5}, []byte("ohhello")},
+		{"offset high byte", big, []byte{0x80, 0x80, 0x04, 3, 0x92, 1, 3}, big[256:259]},
+		{"zero size copies 64 KiB", big, []byte{0x80, 0x80, 0x04, 0x80, 0x80, 0x04, 0x80}, big},
+		{"multi-byte result size", big, []byte{0x80, 0x80, 0x04, 0xc8, 0x01, 0x90, 200}, big[:200]},
+		{"empty result", hello, []byte{12, 0}, []byte{}},
+	}
+	for _, tt := range tests {
+		got, err := applyDelta(tt.base, tt.delta)
+		if err != nil {
+			t.Errorf("%s: applyDelta: %v", tt.name, err)
+			continue
+		}
+		if !bytes.Equal(got, tt.want) {
+			t.Errorf("%s: applyDelta = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestApplyDeltaErrors(t *testing.T) {
+	hello := []byte("hello, world")
+	tests := []struct {
+		name  string
+		delta []byte
+		want  string
+	}{
+		{"no header", nil, "truncated delta"},
+		{"truncated varint", []byte{12, 0x80}, "truncated delta"},
+		{"truncated copy", []byte{12, 1, 0x91}, "truncated delta"},
+		{"truncated insert", []byte{12, 3, 0x03, 'a'}, "truncated delta"},
+		{"copy past base", []byte{12, 13, 0x90, 13}, "delta copies past end of base"},
+		{"reserved instruction", []byte{12, 1, 0x00}, "reserved delta instruction"},
+		{"short result", []byte{12, 5, 0x01, 'a'}, "delta size mismatch"},
+	}
+	for _, tt := range tests {
+		_, err := applyDelta(hello, tt.delta)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: applyDelta error = %v, want %q", tt.name, err, tt.want)
+		}
+	}
+}
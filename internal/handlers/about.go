@@ -8,11 +8,7 @@ import (
 )
 
 func About(w http.ResponseWriter, r *http.Request) {
-    about, err := content.LoadAbout()
-    if err != nil {
-        HandleInternalError(w, r, err)
-        return
-    }
+    about := content.Current().About()
 
 	data := map[string]any{
 		"Title": "About",
@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"journal/internal/content"
+	"journal/internal/models"
+	"journal/internal/render"
+	"journal/internal/router"
+)
+
+// ArchiveLink points at a neighbouring archive period
+type ArchiveLink struct {
+	Label string
+	URL   string
+}
+
+// Archive lists every year and month with entries across all sections
+func Archive(w http.ResponseWriter, r *http.Request) {
+	data := map[string]any{
+		"Title": "Archive",
+		"Years": content.Current().Archive(),
+	}
+
+	if err := render.Render(w, "archive.html", data); err != nil {
+		HandleInternalError(w, r, err)
+	}
+}
+
+// ArchivePeriod lists everything published in /archive/{year} or
+// /archive/{year}/{month}, linking to the neighbouring periods with entries
+func ArchivePeriod(w http.ResponseWriter, r *http.Request) {
+	param, ok := router.ExtractPathParam(r, "/archive/")
+	if !ok {
+		HandleNotFound(w, r)
+		return
+	}
+	parts := strings.Split(param, "/")
+	if len(parts) > 2 {
+		HandleNotFound(w, r)
+		return
+	}
+	year, err := strconv.Atoi(parts[0])
+	if err != nil {
+		HandleNotFound(w, r)
+		return
+	}
+
+	archive := content.Current().Archive()
+	if len(parts) == 1 {
+		archiveYear(w, r, archive, year)
+		return
+	}
+
+	month, err := strconv.Atoi(parts[1])
+	if err != nil || month < 1 || month > 12 {
+		HandleNotFound(w, r)
+		return
+	}
+	archiveMonth(w, r, archive, year, month)
+}
+
+func archiveYear(w http.ResponseWriter, r *http.Request, archive []models.ArchiveYear, year int) {
+	for i, y := range archive {
+		if y.Year != year {
+			continue
+		}
+
+		// The archive is newest first, so the next year along is older
+		var newer, older *ArchiveLink
+		if i > 0 {
+			newer = yearLink(archive[i-1])
+		}
+		if i+1 < len(archive) {
+			older = yearLink(archive[i+1])
+		}
+
+		data := map[string]any{
+			"Title":  strconv.Itoa(year),
+			"Count":  y.Count,
+			"Months": y.Months,
+			"Newer":  newer,
+			"Older":  older,
+		}
+		if err := render.Render(w, "archive_period.html", data); err != nil {
+			HandleInternalError(w, r, err)
+		}
+		return
+	}
+	HandleNotFound(w, r)
+}
+
+func archiveMonth(w http.ResponseWriter, r *http.Request, archive []models.ArchiveYear, year, month int) {
+	var months []models.ArchiveMonth
+	for _, y := range archive {
+		months = append(months, y.Months...)
+	}
+
+	for i, m := range months {
+		if m.Year != year || int(m.Month) != month {
+			continue
+		}
+
+		var newer, older *ArchiveLink
+		if i > 0 {
+			newer = monthLink(months[i-1])
+		}
+		if i+1 < len(months) {
+			older = monthLink(months[i+1])
+		}
+
+		data := map[string]any{
+			"Title":  fmt.Sprintf("%s %d", m.Month, m.Year),
+			"Count":  len(m.Entries),
+			"Months": []models.ArchiveMonth{m},
+			"Newer":  newer,
+			"Older":  older,
+		}
+		if err := render.Render(w, "archive_period.html", data); err != nil {
+			HandleInternalError(w, r, err)
+		}
+		return
+	}
+	HandleNotFound(w, r)
+}
+
+func yearLink(y models.ArchiveYear) *ArchiveLink {
+	return &ArchiveLink{
+		Label: strconv.Itoa(y.Year),
+		URL:   fmt.Sprintf("/archive/%d", y.Year),
+	}
+}
+
+func monthLink(m models.ArchiveMonth) *ArchiveLink {
+	return &ArchiveLink{
+		Label: fmt.Sprintf("%s %d", m.Month, m.Year),
+		URL:   fmt.Sprintf("/archive/%d/%02d", m.Year, int(m.Month)),
+	}
+}
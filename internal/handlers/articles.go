@@ -2,8 +2,6 @@ package handlers
 
 import (
 	"net/http"
-	"sort"
-	"strings"
 
 	"journal/internal/content"
 	"journal/internal/models"
@@ -11,139 +9,73 @@ import (
 	"journal/internal/router"
 )
 
-// ArticlesByYear groups articles by year
+// ArticlesByYear groups articles by year; Continued marks a year carried
+// over from the previous page
 type ArticlesByYear struct {
-	Year     int
-	Articles []models.Article
+	Year      int
+	Articles  []models.Article
+	Continued bool
 }
 
-// getAllTags returns a map of all tags and their counts
-func getAllTags(articles []models.Article) map[string]int {
-	tags := make(map[string]int)
-	for _, article := range articles {
-		for _, tag := range article.Tags {
-			tags[tag]++
+// Articles lists articles grouped by year, pageSize to a page
+func Articles(pageSize int) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		store := content.Current()
+
+		// Filter by ?tags=, using the same syntax as /tags/{query}: commas
+		// for any of the tags, "+" for all of them. The value is read raw,
+		// since decoding it would turn "+" into a space.
+		q := content.ParseTagQuery(router.RawQueryParam(r.URL, "tags"))
+
+		var years, counts []int
+		articlesInYear := make(map[int][]models.Article)
+		allYears, byYear := store.ArticlesByYear()
+		for _, year := range allYears {
+			var yearArticles []models.Article
+			for _, article := range byYear[year] {
+				if q.Matches(article.Tags) {
+					yearArticles = append(yearArticles, article)
+				}
+			}
+			if len(yearArticles) == 0 {
+				continue
+			}
+			articlesInYear[year] = yearArticles
+			years = append(years, year)
+			counts = append(counts, len(yearArticles))
 		}
-	}
-	return tags
-}
-
-// filterArticlesByTag returns articles that have all the specified tags
-func filterArticlesByTag(articles []models.Article, tagQuery string) []models.Article {
-	if tagQuery == "" {
-		return articles
-	}
-
-	// Split the query into individual tags
-	tagFilters := strings.Split(tagQuery, "+")
-	for i := range tagFilters {
-		tagFilters[i] = strings.TrimSpace(tagFilters[i])
-	}
 
-	var filtered []models.Article
-	for _, article := range articles {
-		tagSet := make(map[string]bool)
-		for _, tag := range article.Tags {
-			tagSet[tag] = true
+		pages := pageYears(years, counts, pageSize)
+		page, ok := requestedPage(r, len(pages))
+		if !ok {
+			HandleNotFound(w, r)
+			return
 		}
 
-		allTagsPresent := true
-		for _, filterTag := range tagFilters {
-			if !tagSet[filterTag] {
-				allTagsPresent = false
-				break
+		var articlesByYear []ArticlesByYear
+		if len(pages) > 0 {
+			for _, chunk := range pages[page-1] {
+				articlesByYear = append(articlesByYear, ArticlesByYear{
+					Year:      chunk.Year,
+					Articles:  articlesInYear[chunk.Year][chunk.From:chunk.To],
+					Continued: chunk.Continued,
+				})
 			}
 		}
 
-		if allTagsPresent {
-			filtered = append(filtered, article)
+		data := map[string]any{
+			"Title":          "Articles",
+			"ArticlesByYear": articlesByYear,
+			"Years":          yearLinks(r, pages),
+			"Pagination":     paginate(r, page, len(pages)),
+			"AllTags":        store.ArticleTags(),
+			"CurrentTag":     q.String(),
+			"CurrentTags":    q.Tags(),
 		}
-	}
-
-	return filtered
-}
-
-func Articles(w http.ResponseWriter, r *http.Request) {
-	articles, err := content.LoadArticles()
-	if err != nil {
-		HandleInternalError(w, r, err)
-		return
-	}
-
-	// Get tag filters from query parameter
-	tagQuery := r.URL.Query().Get("tags")
-	var currentTags []string
-	if tagQuery != "" {
-		currentTags = strings.Split(tagQuery, ",")
-		// Filter articles by tags (OR logic - article must have at least one of the selected tags)
-		filteredArticles := make([]models.Article, 0, len(articles))
-		for _, article := range articles {
-			tagSet := make(map[string]bool)
-			for _, t := range article.Tags {
-				tagSet[t] = true
-			}
 
-			// Check if article has any of the selected tags
-			for _, tag := range currentTags {
-				if tagSet[tag] {
-					filteredArticles = append(filteredArticles, article)
-					break
-				}
-			}
+		if err := render.Render(w, "articles.html", data); err != nil {
+			HandleInternalError(w, r, err)
 		}
-		articles = filteredArticles
-	}
-
-	// Sort articles by date (newest first)
-	sort.Slice(articles, func(i, j int) bool {
-		return articles[i].Date.After(articles[j].Date)
-	})
-
-	// Group by year
-	yearMap := make(map[int][]models.Article)
-	for _, article := range articles {
-		year := article.Year()
-		yearMap[year] = append(yearMap[year], article)
-	}
-
-	// Convert to slice and sort years (newest first)
-	var articlesByYear []ArticlesByYear
-	for year, yearArticles := range yearMap {
-		articlesByYear = append(articlesByYear, ArticlesByYear{
-			Year:     year,
-			Articles: yearArticles,
-		})
-	}
-
-	sort.Slice(articlesByYear, func(i, j int) bool {
-		return articlesByYear[i].Year > articlesByYear[j].Year
-	})
-
-	// Get all unique years for navigation
-	years := make([]int, 0, len(yearMap))
-	for year := range yearMap {
-		years = append(years, year)
-	}
-	sort.Sort(sort.Reverse(sort.IntSlice(years)))
-
-	// Get all unique tags from all articles (not just filtered ones)
-	allArticles, err := content.LoadArticles()
-	if err != nil {
-		HandleInternalError(w, r, err)
-		return
-	}
-	allTags := getAllTags(allArticles)
-
-	data := map[string]any{
-		"Title":          "Articles",
-		"ArticlesByYear": articlesByYear,
-		"Years":          years,
-		"AllTags":        allTags,
-		"CurrentTags":    currentTags,
-	}
-
-	if err := render.Render(w, "articles.html", data); err != nil {
-		HandleInternalError(w, r, err)
 	}
 }
 
@@ -154,30 +86,39 @@ func ArticleDetail(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	articles, err := content.LoadArticles()
-	if err != nil {
-		HandleInternalError(w, r, err)
+	store := content.Current()
+	a, ok := store.Article(slug)
+	if !ok {
+		HandleNotFound(w, r)
 		return
 	}
 
-	// Find article by slug
-	for _, a := range articles {
-		if a.Slug == slug {
+	data := articleData(a)
+	data["Section"] = "articles"
+	data["Newer"], data["Older"] = store.ArticleNeighbours(slug)
+	data["Related"] = store.Related("articles", slug)
+	if series, ok := store.Series(a.Series); ok && a.Series != "" {
+		data["Series"] = series
+		data["SeriesPart"] = series.Part(a.Slug)
+	}
 
-			data := map[string]any{
-				"Title":           a.Title,
-				"Date":            a.Date,
-				"Content":         a.HTML,
-				"TableOfContents": a.TableOfContents,
-				"Tags":            a.Tags,
-				"Image":           a.Image,
-			}
-			if err := render.Render(w, "article_detail.html", data); err != nil {
-				HandleInternalError(w, r, err)
-			}
-			return
-		}
+	if err := render.Render(w, "article_detail.html", data); err != nil {
+		HandleInternalError(w, r, err)
 	}
+}
 
-	HandleNotFound(w, r)
+// articleData builds the template data for article_detail.html
+func articleData(a models.Article) map[string]any {
+	return map[string]any{
+		"Slug":            a.Slug,
+		"Title":           a.Title,
+		"Date":            a.Date,
+		"Updated":         a.Updated,
+		"ReadingTime":     a.ReadingTime,
+		"WordCount":       a.WordCount,
+		"Content":         a.HTML,
+		"TableOfContents": a.TableOfContents,
+		"Tags":            a.Tags,
+		"Image":           a.Image,
+	}
 }
@@ -3,6 +3,8 @@ package handlers
 import (
 	"log"
 	"net/http"
+
+	"journal/internal/render"
 )
 
 // ErrorResponse represents an error response
@@ -17,9 +19,13 @@ func HandleError(w http.ResponseWriter, r *http.Request, err error, code int) {
 	http.Error(w, err.Error(), code)
 }
 
-// HandleNotFound returns a 404 response
+// HandleNotFound renders the not-found page with a 404 status
 func HandleNotFound(w http.ResponseWriter, r *http.Request) {
-	http.NotFound(w, r)
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusNotFound)
+	if err := render.Render(w, "not_found.html", nil); err != nil {
+		log.Printf("Error [%s %s]: %v", r.Method, r.URL.Path, err)
+	}
 }
 
 // HandleInternalError returns a 500 response
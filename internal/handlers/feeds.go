@@ -0,0 +1,151 @@
+package handlers
+
+import (
+	"bytes"
+	"net/http"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"journal/internal/config"
+	"journal/internal/content"
+	"journal/internal/feed"
+	"journal/internal/models"
+)
+
+// feedLimit caps how many of the newest entries a feed carries
+const feedLimit = 50
+
+// FeedFiles are the file names served for every feed scope
+var FeedFiles = []string{"feed.xml", "atom.xml", "feed.json"}
+
+// Feed serves RSS (feed.xml), Atom (atom.xml) and JSON Feed (feed.json) for
+// all content at the site root, per section under /articles/ and /fragments/,
+// and per tag query under /tags/{query}/, titled and described after site.
+// Every link is made absolute with baseURL, which must be set: taking it
+// from the request would let a forged Host header into cached feeds.
+func Feed(baseURL string, site config.Site) http.HandlerFunc {
+	base := strings.TrimRight(baseURL, "/")
+	return func(w http.ResponseWriter, r *http.Request) {
+		// escaped, so tag queries keep tags that contain their operators whole
+		dir, file := path.Split(r.URL.EscapedPath())
+		store := content.Current()
+
+		var articles []models.Article
+		var fragments []models.Fragment
+		title, link, description := site.Title, base+"/", site.Description
+		switch {
+		case dir == "/":
+			articles, fragments = store.Articles(), store.Fragments()
+		case dir == "/articles/":
+			articles = store.Articles()
+			title, link = site.Title+" — Articles", base+"/articles"
+			description = "Articles by " + site.Author
+		case dir == "/fragments/":
+			fragments = store.Fragments()
+			title, link = site.Title+" — Fragments", base+"/fragments"
+			description = "Fragments by " + site.Author
+		case strings.HasPrefix(dir, "/tags/"):
+			q := content.ParseTagQuery(strings.Trim(strings.TrimPrefix(dir, "/tags/"), "/"))
+			if q.IsEmpty() {
+				HandleNotFound(w, r)
+				return
+			}
+			tagged := store.Tagged(q)
+			articles, fragments = tagged.Articles, tagged.Fragments
+			title, link = site.Title+" — #"+q.String(), base+"/tags/"+q.Escaped()
+			description = "Entries by " + site.Author + " tagged " + q.String()
+		default:
+			HandleNotFound(w, r)
+			return
+		}
+
+		f := buildFeed(base, articles, fragments)
+		f.Title, f.Link, f.FeedURL, f.Author = title, link, base+r.URL.EscapedPath(), site.Author
+		f.Description = description
+
+		var buf bytes.Buffer
+		var err error
+		switch file {
+		case "feed.xml":
+			w.Header().Set("Content-Type", "application/rss+xml; charset=utf-8")
+			err = feed.WriteRSS(&buf, f)
+		case "atom.xml":
+			w.Header().Set("Content-Type", "application/atom+xml; charset=utf-8")
+			err = feed.WriteAtom(&buf, f)
+		case "feed.json":
+			w.Header().Set("Content-Type", "application/feed+json; charset=utf-8")
+			err = feed.WriteJSON(&buf, f)
+		default:
+			HandleNotFound(w, r)
+			return
+		}
+		if err != nil {
+			HandleInternalError(w, r, err)
+			return
+		}
+		buf.WriteTo(w)
+	}
+}
+
+// buildFeed merges published articles and fragments newest first into
+// feed items; drafts stay out even when the server runs with -drafts
+func buildFeed(base string, articles []models.Article, fragments []models.Fragment) feed.Feed {
+	now := time.Now()
+	var items []feed.Item
+	for _, a := range articles {
+		if !a.IsPublished(now) {
+			continue
+		}
+		u := base + "/articles/" + a.Slug
+		items = append(items, feed.Item{
+			ID:        u,
+			Title:     a.Title,
+			URL:       u,
+			Summary:   a.Summary,
+			Content:   feed.AbsoluteURLs(string(a.HTML), u),
+			Image:     absoluteURL(base, a.Image),
+			Tags:      a.Tags,
+			Published: a.Date,
+			Updated:   lastModified(a.Date, a.Updated),
+
+			WordCount:   a.WordCount,
+			ReadingTime: a.ReadingTime,
+		})
+	}
+	for _, f := range fragments {
+		if !f.IsPublished(now) {
+			continue
+		}
+		u := base + "/fragments/" + f.Slug
+		items = append(items, feed.Item{
+			ID:        u,
+			Title:     f.Title,
+			URL:       u,
+			Content:   feed.AbsoluteURLs(string(f.HTML), u),
+			Image:     absoluteURL(base, f.Image),
+			Tags:      f.Tags,
+			Published: f.Date,
+			Updated:   lastModified(f.Date, f.Updated),
+
+			WordCount:   f.WordCount,
+			ReadingTime: f.ReadingTime,
+		})
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].Published.After(items[j].Published)
+	})
+	if len(items) > feedLimit {
+		items = items[:feedLimit]
+	}
+
+	f := feed.Feed{Items: items}
+	for _, it := range items {
+		if it.Updated.After(f.Updated) {
+			f.Updated = it.Updated
+		}
+	}
+	return f
+}
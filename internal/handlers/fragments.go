@@ -2,7 +2,6 @@ package handlers
 
 import (
 	"net/http"
-	"sort"
 
 	"journal/internal/content"
 	"journal/internal/models"
@@ -10,54 +9,53 @@ import (
 	"journal/internal/router"
 )
 
+// FragmentsByYear groups fragments by year; Continued marks a year carried
+// over from the previous page
 type FragmentsByYear struct {
 	Year      int
 	Fragments []models.Fragment
+	Continued bool
 }
 
-func Fragments(w http.ResponseWriter, r *http.Request) {
-	fragments, err := content.LoadFragments()
-	if err != nil {
-		HandleInternalError(w, r, err)
-		return
-	}
-
-	sort.Slice(fragments, func(i, j int) bool {
-		return fragments[i].Date.After(fragments[j].Date)
-	})
+// Fragments lists fragments grouped by year, pageSize to a page
+func Fragments(pageSize int) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		store := content.Current()
 
-	yearMap := make(map[int][]models.Fragment)
-	for _, fragment := range fragments {
-		year := fragment.Year()
-		yearMap[year] = append(yearMap[year], fragment)
-	}
-
-	var fragmentsByYear []FragmentsByYear
-	for year, yearFragments := range yearMap {
-		fragmentsByYear = append(fragmentsByYear, FragmentsByYear{
-			Year:      year,
-			Fragments: yearFragments,
-		})
-	}
+		years, fragmentsInYear := store.FragmentsByYear()
+		counts := make([]int, len(years))
+		for i, year := range years {
+			counts[i] = len(fragmentsInYear[year])
+		}
 
-	sort.Slice(fragmentsByYear, func(i, j int) bool {
-		return fragmentsByYear[i].Year > fragmentsByYear[j].Year
-	})
+		pages := pageYears(years, counts, pageSize)
+		page, ok := requestedPage(r, len(pages))
+		if !ok {
+			HandleNotFound(w, r)
+			return
+		}
 
-	years := make([]int, 0, len(yearMap))
-	for year := range yearMap {
-		years = append(years, year)
-	}
-	sort.Sort(sort.Reverse(sort.IntSlice(years)))
+		var fragmentsByYear []FragmentsByYear
+		if len(pages) > 0 {
+			for _, chunk := range pages[page-1] {
+				fragmentsByYear = append(fragmentsByYear, FragmentsByYear{
+					Year:      chunk.Year,
+					Fragments: fragmentsInYear[chunk.Year][chunk.From:chunk.To],
+					Continued: chunk.Continued,
+				})
+			}
+		}
 
-	data := map[string]any{
-		"Title":           "Fragments",
-		"FragmentsByYear": fragmentsByYear,
-		"Years":           years,
-	}
+		data := map[string]any{
+			"Title":           "Fragments",
+			"FragmentsByYear": fragmentsByYear,
+			"Years":           yearLinks(r, pages),
+			"Pagination":      paginate(r, page, len(pages)),
+		}
 
-	if err := render.Render(w, "fragments.html", data); err != nil {
-		HandleInternalError(w, r, err)
+		if err := render.Render(w, "fragments.html", data); err != nil {
+			HandleInternalError(w, r, err)
+		}
 	}
 }
 
@@ -68,26 +66,33 @@ func FragmentDetail(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	fragments, err := content.LoadFragments()
-	if err != nil {
-		HandleInternalError(w, r, err)
+	store := content.Current()
+	f, ok := store.Fragment(slug)
+	if !ok {
+		HandleNotFound(w, r)
 		return
 	}
 
-	for _, f := range fragments {
-		if f.Slug == slug {
-			data := map[string]any{
-				"Title":   f.Title,
-				"Date":    f.Date,
-				"Image":   f.Image,
-				"Content": f.HTML,
-			}
-			if err := render.Render(w, "fragment_detail.html", data); err != nil {
-				HandleInternalError(w, r, err)
-			}
-			return
-		}
+	data := fragmentData(f)
+	data["Section"] = "fragments"
+	data["Newer"], data["Older"] = store.FragmentNeighbours(slug)
+	data["Related"] = store.Related("fragments", slug)
+
+	if err := render.Render(w, "fragment_detail.html", data); err != nil {
+		HandleInternalError(w, r, err)
 	}
+}
 
-	HandleNotFound(w, r)
+// fragmentData builds the template data for fragment_detail.html
+func fragmentData(f models.Fragment) map[string]any {
+	return map[string]any{
+		"Title":       f.Title,
+		"Date":        f.Date,
+		"Updated":     f.Updated,
+		"ReadingTime": f.ReadingTime,
+		"WordCount":   f.WordCount,
+		"Image":       f.Image,
+		"Tags":        f.Tags,
+		"Content":     f.HTML,
+	}
 }
@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+)
+
+// Pagination places a listing page among its siblings; templates render it
+// with the "pagination" block from base.html, which also emits <link rel>
+// tags for it in the page head
+type Pagination struct {
+	Page    int
+	Pages   int
+	PrevURL string
+	NextURL string
+}
+
+// YearLink points the years sidebar at the page holding a year's heading
+type YearLink struct {
+	Year int
+	URL  string
+}
+
+// yearChunk is the run of a year's entries [From, To) shown on one page.
+// Continued marks a year that started on an earlier page, so its heading is
+// not repeated.
+type yearChunk struct {
+	Year      int
+	From, To  int
+	Continued bool
+}
+
+// pageYears splits years into pages of at most pageSize entries, where
+// counts[i] is how many entries years[i] holds. A year stays on one page when
+// it fits; one larger than a page is cut into page-sized chunks.
+func pageYears(years, counts []int, pageSize int) [][]yearChunk {
+	var pages [][]yearChunk
+	var page []yearChunk
+	size := 0
+	for i, year := range years {
+		n := counts[i]
+		if size > 0 && size+n > pageSize {
+			pages, page, size = append(pages, page), nil, 0
+		}
+		for from := 0; from < n; {
+			to := min(n, from+pageSize-size)
+			page = append(page, yearChunk{Year: year, From: from, To: to, Continued: from > 0})
+			size += to - from
+			from = to
+			if size == pageSize {
+				pages, page, size = append(pages, page), nil, 0
+			}
+		}
+	}
+	if len(page) > 0 {
+		pages = append(pages, page)
+	}
+	return pages
+}
+
+// yearLinks links each year to the first page it appears on
+func yearLinks(r *http.Request, pages [][]yearChunk) []YearLink {
+	var links []YearLink
+	for i, page := range pages {
+		for _, chunk := range page {
+			if !chunk.Continued {
+				links = append(links, YearLink{
+					Year: chunk.Year,
+					URL:  pageURL(r, i+1) + "#" + strconv.Itoa(chunk.Year),
+				})
+			}
+		}
+	}
+	return links
+}
+
+// pageCount returns how many pages of pageSize it takes to show n entries
+func pageCount(n, pageSize int) int {
+	return (n + pageSize - 1) / pageSize
+}
+
+// requestedPage reads ?page=, defaulting to 1; ok is false when the value is
+// malformed or past the last page
+func requestedPage(r *http.Request, pages int) (page int, ok bool) {
+	raw := r.URL.Query().Get("page")
+	if raw == "" {
+		return 1, true
+	}
+	page, err := strconv.Atoi(raw)
+	if err != nil || page < 1 || page > max(pages, 1) {
+		return 0, false
+	}
+	return page, true
+}
+
+// paginate describes page of pages for the current request's URL
+func paginate(r *http.Request, page, pages int) Pagination {
+	p := Pagination{Page: page, Pages: pages}
+	if page > 1 {
+		p.PrevURL = pageURL(r, page-1)
+	}
+	if page < pages {
+		p.NextURL = pageURL(r, page+1)
+	}
+	return p
+}
+
+// pageURL is the current URL pointed at another page, keeping other query
+// parameters such as ?tags=; page 1 drops the parameter
+func pageURL(r *http.Request, page int) string {
+	q := r.URL.Query()
+	if page == 1 {
+		q.Del("page")
+	} else {
+		q.Set("page", strconv.Itoa(page))
+	}
+	if len(q) == 0 {
+		return r.URL.Path
+	}
+	return r.URL.Path + "?" + q.Encode()
+}
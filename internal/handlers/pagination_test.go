@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPageYears(t *testing.T) {
+	tests := []struct {
+		name   string
+		years  []int
+		counts []int
+		want   [][]yearChunk
+	}{
+		{
+			name: "empty",
+		},
+		{
+			name:   "all on one page",
+			years:  []int{2025, 2024},
+			counts: []int{3, 2},
+			want: [][]yearChunk{
+				{{Year: 2025, From: 0, To: 3}, {Year: 2024, From: 0, To: 2}},
+			},
+		},
+		{
+			name:   "a year that does not fit starts the next page",
+			years:  []int{2025, 2024},
+			counts: []int{6, 5},
+			want: [][]yearChunk{
+				{{Year: 2025, From: 0, To: 6}},
+				{{Year: 2024, From: 0, To: 5}},
+			},
+		},
+		{
+			name:   "a full page closes",
+			years:  []int{2025, 2024, 2023},
+			counts: []int{5, 5, 1},
+			want: [][]yearChunk{
+				{{Year: 2025, From: 0, To: 5}, {Year: 2024, From: 0, To: 5}},
+				{{Year: 2023, From: 0, To: 1}},
+			},
+		},
+		{
+			name:   "a year larger than a page is cut",
+			years:  []int{2025, 2024},
+			counts: []int{25, 3},
+			want: [][]yearChunk{
+				{{Year: 2025, From: 0, To: 10}},
+				{{Year: 2025, From: 10, To: 20, Continued: true}},
+				{{Year: 2025, From: 20, To: 25, Continued: true}, {Year: 2024, From: 0, To: 3}},
+			},
+		},
+		{
+			name:   "a large year after a partial page starts fresh",
+			years:  []int{2025, 2024},
+			counts: []int{3, 12},
+			want: [][]yearChunk{
+				{{Year: 2025, From: 0, To: 3}},
+				{{Year: 2024, From: 0, To: 10}},
+				{{Year: 2024, From: 10, To: 12, Continued: true}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		if got := pageYears(tt.years, tt.counts, 10); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: pageYears(%v, %v, 10) = %v, want %v", tt.name, tt.years, tt.counts, got, tt.want)
+		}
+	}
+}
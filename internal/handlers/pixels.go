@@ -2,29 +2,69 @@ package handlers
 
 import (
 	"net/http"
-	"sort"
 
 	"journal/internal/content"
+	"journal/internal/models"
     "journal/internal/render"
+    "journal/internal/router"
 )
 
-func Pixels(w http.ResponseWriter, r *http.Request) {
-    pixels, err := content.LoadPixels()
-    if err != nil {
-        HandleInternalError(w, r, err)
-        return
+// Pixels lists pixels newest first, pageSize to a page
+func Pixels(pageSize int) http.HandlerFunc {
+    return func(w http.ResponseWriter, r *http.Request) {
+        pixels := content.Current().Pixels()
+
+        pages := pageCount(len(pixels), pageSize)
+        page, ok := requestedPage(r, pages)
+        if !ok {
+            HandleNotFound(w, r)
+            return
+        }
+        from := (page - 1) * pageSize
+        to := min(len(pixels), from+pageSize)
+
+        data := map[string]any{
+            "Title" : "Pixels",
+            "Pixels": pixels[from:to],
+            "Pagination": paginate(r, page, pages),
+        }
+
+        if err := render.Render(w, "pixels.html", data); err != nil {
+            HandleInternalError(w, r, err)
+        }
     }
+}
 
-    sort.Slice(pixels, func(i, j int) bool {
-        return pixels[i].Date.After(pixels[j].Date)
-    })
+// PixelDetail renders a single pixel with links to its neighbours in the feed
+func PixelDetail(w http.ResponseWriter, r *http.Request) {
+    slug, ok := router.ExtractPathParam(r, "/pixels/")
+    if !ok || slug == "" {
+        HandleNotFound(w, r)
+        return
+    }
 
-    data := map[string]any{
-        "Title" : "Pixels",
-        "Pixels": pixels,
+    store := content.Current()
+    p, ok := store.Pixel(slug)
+    if !ok {
+        HandleNotFound(w, r)
+        return
     }
 
-    if err := render.Render(w, "pixels.html", data); err != nil {
+    data := pixelData(p)
+    data["Newer"], data["Older"] = store.PixelNeighbours(slug)
+
+    if err := render.Render(w, "pixel_detail.html", data); err != nil {
         HandleInternalError(w, r, err)
     }
-}
\ No newline at end of file
+}
+
+// pixelData builds the template data for pixel_detail.html
+func pixelData(p models.Pixel) map[string]any {
+    return map[string]any{
+        "Title":   p.Title,
+        "Date":    p.Date,
+        "Updated": p.Updated,
+        "Image":   p.Image,
+        "Content": p.HTML,
+    }
+}
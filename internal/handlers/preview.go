@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"journal/internal/content"
+	"journal/internal/preview"
+	"journal/internal/render"
+	"journal/internal/router"
+)
+
+// Preview serves unpublished entries at /preview/{section}/{slug}?token=...
+// through the normal detail templates, for reviewers holding a signed link
+func Preview(secret []byte) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		path, ok := router.ExtractPathParam(r, "/preview/")
+		if !ok || len(secret) == 0 {
+			HandleNotFound(w, r)
+			return
+		}
+		section, slug, ok := strings.Cut(path, "/")
+		if !ok || slug == "" {
+			HandleNotFound(w, r)
+			return
+		}
+
+		token := r.URL.Query().Get("token")
+		if err := preview.Verify(secret, section, slug, token, time.Now()); err != nil {
+			HandleError(w, r, err, http.StatusForbidden)
+			return
+		}
+
+		store := content.Current()
+		var tmpl string
+		var data map[string]any
+		switch section {
+		case "articles":
+			a, ok := store.DraftArticle(slug)
+			if !ok {
+				HandleNotFound(w, r)
+				return
+			}
+			tmpl, data = "article_detail.html", articleData(a)
+		case "fragments":
+			f, ok := store.DraftFragment(slug)
+			if !ok {
+				HandleNotFound(w, r)
+				return
+			}
+			tmpl, data = "fragment_detail.html", fragmentData(f)
+		case "shelf":
+			item, ok := store.DraftShelfItem(slug)
+			if !ok {
+				HandleNotFound(w, r)
+				return
+			}
+			tmpl, data = "shelf_detail.html", shelfItemData(item)
+		default:
+			HandleNotFound(w, r)
+			return
+		}
+
+		// Keep previews out of search engines and shared caches
+		w.Header().Set("X-Robots-Tag", "noindex, nofollow")
+		w.Header().Set("Cache-Control", "private, no-store")
+		data["Preview"] = true
+
+		if err := render.Render(w, tmpl, data); err != nil {
+			HandleInternalError(w, r, err)
+		}
+	}
+}
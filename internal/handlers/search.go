@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"journal/internal/content"
+	"journal/internal/render"
+)
+
+// searchLimit caps how many results a query returns
+const searchLimit = 50
+
+// Search renders ranked results for /search?q=...
+func Search(w http.ResponseWriter, r *http.Request) {
+	query := strings.TrimSpace(r.URL.Query().Get("q"))
+	results := content.Current().Search(query, searchLimit)
+
+	data := map[string]any{
+		"Title":   "Search",
+		"Query":   query,
+		"Results": results,
+	}
+
+	if err := render.Render(w, "search.html", data); err != nil {
+		HandleInternalError(w, r, err)
+	}
+}
+
+// SearchJSON returns the same results as /search for scripting
+func SearchJSON(w http.ResponseWriter, r *http.Request) {
+	type result struct {
+		Section string    `json:"section"`
+		Slug    string    `json:"slug"`
+		Title   string    `json:"title"`
+		URL     string    `json:"url"`
+		Date    time.Time `json:"date"`
+		Score   float64   `json:"score"`
+		Snippet string    `json:"snippet"`
+	}
+
+	query := strings.TrimSpace(r.URL.Query().Get("q"))
+	out := []result{}
+	for _, res := range content.Current().Search(query, searchLimit) {
+		out = append(out, result{
+			Section: res.Section,
+			Slug:    res.Slug,
+			Title:   res.Title,
+			URL:     res.URL,
+			Date:    res.Date,
+			Score:   res.Score,
+			Snippet: string(res.Snippet),
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	if err := json.NewEncoder(w).Encode(map[string]any{"query": query, "results": out}); err != nil {
+		HandleInternalError(w, r, err)
+	}
+}
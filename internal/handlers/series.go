@@ -0,0 +1,33 @@
+package handlers
+
+import (
+	"net/http"
+
+	"journal/internal/content"
+	"journal/internal/render"
+	"journal/internal/router"
+)
+
+// SeriesDetail lists the parts of /series/{name} in reading order
+func SeriesDetail(w http.ResponseWriter, r *http.Request) {
+	name, ok := router.ExtractPathParam(r, "/series/")
+	if !ok || name == "" {
+		HandleNotFound(w, r)
+		return
+	}
+
+	series, ok := content.Current().Series(name)
+	if !ok {
+		HandleNotFound(w, r)
+		return
+	}
+
+	data := map[string]any{
+		"Title":  series.Name,
+		"Series": series,
+	}
+
+	if err := render.Render(w, "series.html", data); err != nil {
+		HandleInternalError(w, r, err)
+	}
+}
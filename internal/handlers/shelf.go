@@ -2,8 +2,8 @@ package handlers
 
 import (
 	"net/http"
-	"sort"
 
+	"journal/internal/config"
 	"journal/internal/content"
 	"journal/internal/models"
 	"journal/internal/render"
@@ -16,52 +16,44 @@ type ShelfSection struct {
 	Items []models.ShelfItem
 }
 
-var shelfSections = []ShelfSection{
-	{Key: "books", Title: "Bookshelf"},
-	{Key: "papers", Title: "Papers"},
-	{Key: "misc", Title: "Miscellany"},
-}
-
-func Shelf(w http.ResponseWriter, r *http.Request) {
-	items, err := content.LoadShelfItems()
-	if err != nil {
-		HandleInternalError(w, r, err)
-		return
-	}
+// Shelf lists shelf items grouped under categories, in the order given;
+// items without a category go under the first and items in other
+// categories are not shown
+func Shelf(categories []config.ShelfCategory) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		items := content.Current().ShelfItems()
 
-	sectionMap := make(map[string][]models.ShelfItem)
-	for _, item := range items {
-		key := item.Category
-		if key == "" {
-			key = "books"
+		sectionMap := make(map[string][]models.ShelfItem)
+		for _, item := range items {
+			key := item.Category
+			if key == "" && len(categories) > 0 {
+				key = categories[0].Key
+			}
+			sectionMap[key] = append(sectionMap[key], item)
 		}
-		sectionMap[key] = append(sectionMap[key], item)
-	}
 
-	var sections []ShelfSection
-	for _, def := range shelfSections {
-		entries := sectionMap[def.Key]
-		sort.Slice(entries, func(i, j int) bool {
-			return entries[i].Date.After(entries[j].Date)
-		})
-		if len(entries) == 0 {
-			continue
+		var sections []ShelfSection
+		for _, def := range categories {
+			entries := sectionMap[def.Key]
+			if len(entries) == 0 {
+				continue
+			}
+			sections = append(sections, ShelfSection{
+				Key:   def.Key,
+				Title: def.Title,
+				Items: entries,
+			})
 		}
-		sections = append(sections, ShelfSection{
-			Key:   def.Key,
-			Title: def.Title,
-			Items: entries,
-		})
-	}
 
-	data := map[string]any{
-		"Title":         "Shelf",
-		"ShelfNav":      shelfSections,
-		"ShelfSections": sections,
-	}
+		data := map[string]any{
+			"Title":         "Shelf",
+			"ShelfNav":      categories,
+			"ShelfSections": sections,
+		}
 
-	if err := render.Render(w, "shelf.html", data); err != nil {
-		HandleInternalError(w, r, err)
+		if err := render.Render(w, "shelf.html", data); err != nil {
+			HandleInternalError(w, r, err)
+		}
 	}
 }
 
@@ -72,26 +64,27 @@ func ShelfDetail(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	items, err := content.LoadShelfItems()
-	if err != nil {
-		HandleInternalError(w, r, err)
+	item, ok := content.Current().ShelfItem(slug)
+	if !ok {
+		HandleNotFound(w, r)
 		return
 	}
 
-	for _, item := range items {
-		if item.Slug == slug {
-			data := map[string]any{
-				"Title":    item.Title,
-				"Date":     item.Date,
-				"Category": item.Category,
-				"Content":  item.HTML,
-			}
-			if err := render.Render(w, "shelf_detail.html", data); err != nil {
-				HandleInternalError(w, r, err)
-			}
-			return
-		}
+	if err := render.Render(w, "shelf_detail.html", shelfItemData(item)); err != nil {
+		HandleInternalError(w, r, err)
 	}
+}
 
-	HandleNotFound(w, r)
+// shelfItemData builds the template data for shelf_detail.html
+func shelfItemData(item models.ShelfItem) map[string]any {
+	return map[string]any{
+		"Title":       item.Title,
+		"Date":        item.Date,
+		"Updated":     item.Updated,
+		"ReadingTime": item.ReadingTime,
+		"WordCount":   item.WordCount,
+		"Category":    item.Category,
+		"Tags":        item.Tags,
+		"Content":     item.HTML,
+	}
 }
@@ -0,0 +1,229 @@
+package handlers
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"journal/internal/config"
+	"journal/internal/content"
+	"journal/internal/render"
+	"journal/internal/router"
+	"journal/internal/sitemap"
+)
+
+// Sitemap serves /sitemap.xml for the enabled sections, switching to a
+// sitemap index with parts at /sitemaps/{n}.xml once the site outgrows a
+// single file. Like feeds, it needs baseURL for its absolute links.
+func Sitemap(baseURL string, sections []string) http.HandlerFunc {
+	base := strings.TrimRight(baseURL, "/")
+	return func(w http.ResponseWriter, r *http.Request) {
+		parts := sitemap.Split(sitemapURLs(base, sections))
+
+		var buf bytes.Buffer
+		var err error
+		if r.URL.Path == "/sitemap.xml" {
+			if len(parts) == 1 {
+				err = sitemap.Write(&buf, parts[0])
+			} else {
+				var index []sitemap.Index
+				for i, part := range parts {
+					index = append(index, sitemap.Index{
+						Loc:     fmt.Sprintf("%s/sitemaps/%d.xml", base, i+1),
+						LastMod: newest(part),
+					})
+				}
+				err = sitemap.WriteIndex(&buf, index)
+			}
+		} else {
+			name, _ := router.ExtractPathParam(r, "/sitemaps/")
+			n, convErr := strconv.Atoi(strings.TrimSuffix(name, ".xml"))
+			if convErr != nil || !strings.HasSuffix(name, ".xml") || n < 1 || n > len(parts) || len(parts) == 1 {
+				HandleNotFound(w, r)
+				return
+			}
+			err = sitemap.Write(&buf, parts[n-1])
+		}
+		if err != nil {
+			HandleInternalError(w, r, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+		buf.WriteTo(w)
+	}
+}
+
+// Robots serves /robots.txt from rules (a default when empty), pointing
+// crawlers at the sitemap when there is one
+func Robots(baseURL, rules string) http.HandlerFunc {
+	if rules == "" {
+		rules = "User-agent: *\nDisallow: /preview/\n"
+	}
+	rules = strings.TrimRight(rules, "\n") + "\n"
+	if baseURL != "" && !strings.Contains(strings.ToLower(rules), "sitemap:") {
+		rules += "\nSitemap: " + strings.TrimRight(baseURL, "/") + "/sitemap.xml\n"
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.Write([]byte(rules))
+	}
+}
+
+// sitemapURLs lists every published page of the enabled sections, and the
+// tag, archive and series pages built from them, with its last
+// modification date. Paginated listing pages and multi-tag queries are left
+// out, since they only repeat pages already listed.
+func sitemapURLs(base string, sections []string) []sitemap.URL {
+	store := content.Current()
+	now := time.Now()
+
+	// A tag or series page changes whenever an entry it lists does
+	tagMods := make(map[string]time.Time)
+	seriesMods := make(map[string]time.Time)
+	touch := func(mods map[string]time.Time, key string, t time.Time) {
+		if t.After(mods[key]) {
+			mods[key] = t
+		}
+	}
+
+	var articles, fragments, shelf, pixels []sitemap.URL
+	for _, a := range store.Articles() {
+		if a.IsPublished(now) {
+			mod := lastModified(a.Date, a.Updated)
+			articles = append(articles, sitemap.URL{Loc: base + "/articles/" + a.Slug, LastMod: mod})
+			for _, tag := range a.Tags {
+				touch(tagMods, tag, mod)
+			}
+			if a.Series != "" {
+				touch(seriesMods, render.Slugify(a.Series), mod)
+			}
+		}
+	}
+	for _, f := range store.Fragments() {
+		if f.IsPublished(now) {
+			mod := lastModified(f.Date, f.Updated)
+			fragments = append(fragments, sitemap.URL{Loc: base + "/fragments/" + f.Slug, LastMod: mod})
+			for _, tag := range f.Tags {
+				touch(tagMods, tag, mod)
+			}
+		}
+	}
+	for _, item := range store.ShelfItems() {
+		if item.IsPublished(now) {
+			mod := lastModified(item.Date, item.Updated)
+			shelf = append(shelf, sitemap.URL{Loc: base + "/shelf/" + item.Slug, LastMod: mod})
+			for _, tag := range item.Tags {
+				touch(tagMods, tag, mod)
+			}
+		}
+	}
+	for _, p := range store.Pixels() {
+		if !p.IsPublished(now) {
+			continue
+		}
+		u := sitemap.URL{Loc: base + "/pixels/" + p.Slug, LastMod: lastModified(p.Date, p.Updated)}
+		if p.Image != "" {
+			u.Images = []string{absoluteURL(base, p.Image)}
+		}
+		pixels = append(pixels, u)
+	}
+
+	listings := map[string]time.Time{
+		"articles":  newest(articles),
+		"fragments": newest(fragments),
+		"shelf":     newest(shelf),
+		"pixels":    newest(pixels),
+		"about":     store.About().Date,
+	}
+	urls := []sitemap.URL{{Loc: base + "/"}}
+	for _, section := range config.Sections {
+		if !slices.Contains(sections, section) {
+			continue
+		}
+		urls = append(urls, sitemap.URL{Loc: base + "/" + section, LastMod: listings[section]})
+	}
+
+	var tags []sitemap.URL
+	for _, tag := range store.Tags() {
+		tags = append(tags, sitemap.URL{Loc: base + "/tags/" + render.EscapeTag(tag.Name), LastMod: tagMods[tag.Name]})
+	}
+	if len(tags) > 0 {
+		urls = append(urls, sitemap.URL{Loc: base + "/tags", LastMod: newest(tags)})
+		urls = append(urls, tags...)
+	}
+
+	var archive []sitemap.URL
+	for _, y := range store.Archive() {
+		var months []sitemap.URL
+		for _, m := range y.Months {
+			months = append(months, sitemap.URL{
+				Loc:     fmt.Sprintf("%s/archive/%d/%02d", base, m.Year, int(m.Month)),
+				LastMod: m.Entries[0].Date,
+			})
+		}
+		archive = append(archive, sitemap.URL{Loc: fmt.Sprintf("%s/archive/%d", base, y.Year), LastMod: newest(months)})
+		archive = append(archive, months...)
+	}
+	if len(archive) > 0 {
+		urls = append(urls, sitemap.URL{Loc: base + "/archive", LastMod: newest(archive)})
+		urls = append(urls, archive...)
+	}
+
+	seriesSlugs := make([]string, 0, len(seriesMods))
+	for slug := range seriesMods {
+		seriesSlugs = append(seriesSlugs, slug)
+	}
+	slices.Sort(seriesSlugs)
+	for _, slug := range seriesSlugs {
+		urls = append(urls, sitemap.URL{Loc: base + "/series/" + slug, LastMod: seriesMods[slug]})
+	}
+
+	urls = append(urls, pixels...)
+	urls = append(urls, articles...)
+	urls = append(urls, fragments...)
+	urls = append(urls, shelf...)
+	return urls
+}
+
+// newest returns the latest LastMod among urls
+func newest(urls []sitemap.URL) time.Time {
+	var latest time.Time
+	for _, u := range urls {
+		if u.LastMod.After(latest) {
+			latest = u.LastMod
+		}
+	}
+	return latest
+}
+
+// lastModified returns updated when an entry has been revised, else date
+func lastModified(date, updated time.Time) time.Time {
+	if updated.After(date) {
+		return updated
+	}
+	return date
+}
+
+// absoluteURL resolves ref against base, leaving absolute URLs and an
+// empty ref untouched
+func absoluteURL(base, ref string) string {
+	if ref == "" {
+		return ""
+	}
+	b, err := url.Parse(base + "/")
+	if err != nil {
+		return ref
+	}
+	u, err := url.Parse(ref)
+	if err != nil {
+		return ref
+	}
+	return b.ResolveReference(u).String()
+}
@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"journal/internal/content"
+	"journal/internal/render"
+)
+
+// Tags renders the tag cloud across articles, fragments and shelf items
+func Tags(w http.ResponseWriter, r *http.Request) {
+	data := map[string]any{
+		"Title": "Tags",
+		"Tags":  content.Current().Tags(),
+	}
+
+	if err := render.Render(w, "tags.html", data); err != nil {
+		HandleInternalError(w, r, err)
+	}
+}
+
+// TagDetail lists everything matching /tags/{query}, where the query uses the
+// same syntax as ?tags= on /articles: "a,b" for either tag, "a+b" for both.
+// The query is read from the escaped path so escaped tags stay whole.
+func TagDetail(w http.ResponseWriter, r *http.Request) {
+	raw := strings.Trim(strings.TrimPrefix(r.URL.EscapedPath(), "/tags/"), "/")
+	if raw == "" {
+		HandleNotFound(w, r)
+		return
+	}
+
+	q := content.ParseTagQuery(raw)
+	if q.IsEmpty() {
+		HandleNotFound(w, r)
+		return
+	}
+
+	tagged := content.Current().Tagged(q)
+	if tagged.Len() == 0 {
+		HandleNotFound(w, r)
+		return
+	}
+
+	data := map[string]any{
+		"Title":      "#" + q.String(),
+		"Query":      q.String(),
+		"QueryPath":  q.Escaped(),
+		"Tags":       q.Tags(),
+		"Articles":   tagged.Articles,
+		"Fragments":  tagged.Fragments,
+		"ShelfItems": tagged.ShelfItems,
+		"Count":      tagged.Len(),
+	}
+
+	if err := render.Render(w, "tag.html", data); err != nil {
+		HandleInternalError(w, r, err)
+	}
+}
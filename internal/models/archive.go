@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+// ArchiveEntry is one dated entry from any section, as listed in the archive
+type ArchiveEntry struct {
+	Section string
+	Slug    string
+	Title   string
+	URL     string
+	Date    time.Time
+}
+
+// ArchiveMonth holds a month's entries across sections, newest first
+type ArchiveMonth struct {
+	Year    int
+	Month   time.Month
+	Entries []ArchiveEntry
+}
+
+// ArchiveYear holds the months of a year that have entries, newest first
+type ArchiveYear struct {
+	Year   int
+	Count  int
+	Months []ArchiveMonth
+}
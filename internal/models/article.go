@@ -9,14 +9,33 @@ type Article struct {
 	Slug            string
 	Title           string
 	Summary         string
+	Excerpt         template.HTML // rendered text before <!--more-->, if any
 	Date            time.Time
+	Updated         time.Time // zero unless revised after Date
 	HTML            template.HTML
+	PlainText       string
+	WordCount       int
+	ReadingTime     int // minutes
 	TableOfContents template.HTML
 	Tags            []string
 	Image           string
+	Series          string
+	SeriesOrder     int
+	Draft           bool
+	PublishAt       time.Time
 }
 
 // Year returns the year the article was published
 func (a Article) Year() int {
 	return a.Date.Year()
 }
+
+// IsPublished reports whether the article is visible to readers at now
+func (a Article) IsPublished(now time.Time) bool {
+	return !a.Draft && !a.PublishAt.After(now)
+}
+
+// PublishTime returns when the article goes live
+func (a Article) PublishTime() time.Time {
+	return a.PublishAt
+}
@@ -6,13 +6,31 @@ import (
 )
 
 type Fragment struct {
-	Slug  string
-	Title string
-	Image string
-	Date  time.Time
-	HTML  template.HTML
+	Slug        string
+	Title       string
+	Image       string
+	Date        time.Time
+	Updated     time.Time // zero unless revised after Date
+	HTML        template.HTML
+	Excerpt     template.HTML // rendered text before <!--more-->, if any
+	PlainText   string
+	WordCount   int
+	ReadingTime int // minutes
+	Tags        []string
+	Draft       bool
+	PublishAt   time.Time
 }
 
 func (f Fragment) Year() int {
 	return f.Date.Year()
 }
+
+// IsPublished reports whether the fragment is visible to readers at now
+func (f Fragment) IsPublished(now time.Time) bool {
+	return !f.Draft && !f.PublishAt.After(now)
+}
+
+// PublishTime returns when the fragment goes live
+func (f Fragment) PublishTime() time.Time {
+	return f.PublishAt
+}
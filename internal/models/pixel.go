@@ -6,9 +6,23 @@ import  (
 )
 
 type Pixel struct {
-    Slug  string
-    Title string
-    Image string
-    Date  time.Time
-    HTML  template.HTML
+    Slug      string
+    Title     string
+    Image     string
+    Date      time.Time
+    Updated   time.Time // zero unless revised after Date
+    HTML      template.HTML
+    PlainText string
+    Draft     bool
+    PublishAt time.Time
+}
+
+// IsPublished reports whether the pixel is visible to readers at now
+func (p Pixel) IsPublished(now time.Time) bool {
+    return !p.Draft && !p.PublishAt.After(now)
+}
+
+// PublishTime returns when the pixel goes live
+func (p Pixel) PublishTime() time.Time {
+    return p.PublishAt
 }
@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+// Related is an entry recommended alongside another, best match first
+type Related struct {
+	Section string
+	Slug    string
+	Title   string
+	URL     string
+	Date    time.Time
+	Score   float64
+}
@@ -0,0 +1,18 @@
+package models
+
+// Series is a run of articles meant to be read in order
+type Series struct {
+	Name  string
+	Slug  string
+	Parts []Article
+}
+
+// Part returns the 1-based position of the article with slug, or 0
+func (s Series) Part(slug string) int {
+	for i, a := range s.Parts {
+		if a.Slug == slug {
+			return i + 1
+		}
+	}
+	return 0
+}
@@ -6,14 +6,31 @@ import (
 )
 
 type ShelfItem struct {
-	Slug     string
-	Title    string
-	Category string
-	Summary  string
-	Date     time.Time
-	HTML     template.HTML
+	Slug        string
+	Title       string
+	Category    string
+	Summary     string
+	Date        time.Time
+	Updated     time.Time // zero unless revised after Date
+	HTML        template.HTML
+	PlainText   string
+	WordCount   int
+	ReadingTime int // minutes
+	Tags        []string
+	Draft       bool
+	PublishAt   time.Time
 }
 
 func (s ShelfItem) Year() int {
 	return s.Date.Year()
 }
+
+// IsPublished reports whether the shelf item is visible to readers at now
+func (s ShelfItem) IsPublished(now time.Time) bool {
+	return !s.Draft && !s.PublishAt.After(now)
+}
+
+// PublishTime returns when the shelf item goes live
+func (s ShelfItem) PublishTime() time.Time {
+	return s.PublishAt
+}
@@ -0,0 +1,9 @@
+package models
+
+// Tag is a tag with how many published entries carry it
+type Tag struct {
+	Name  string
+	Count int
+	// Weight ranks the tag from 1 (rare) to 5 (most used) for the tag cloud
+	Weight int
+}
@@ -0,0 +1,51 @@
+package preview
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidToken = errors.New("invalid preview token")
+	ErrExpiredToken = errors.New("preview link has expired")
+)
+
+// Sign returns a token granting access to one entry until expires.
+// Format: "<unix expiry>.<base64url HMAC-SHA256 of section, slug and expiry>"
+func Sign(secret []byte, section, slug string, expires time.Time) string {
+	exp := strconv.FormatInt(expires.Unix(), 10)
+	return exp + "." + mac(secret, section, slug, exp)
+}
+
+// Verify checks that token was minted with secret for this entry and has not expired
+func Verify(secret []byte, section, slug, token string, now time.Time) error {
+	exp, sig, ok := strings.Cut(token, ".")
+	if !ok || len(secret) == 0 {
+		return ErrInvalidToken
+	}
+
+	expected := mac(secret, section, slug, exp)
+	if !hmac.Equal([]byte(sig), []byte(expected)) {
+		return ErrInvalidToken
+	}
+
+	unix, err := strconv.ParseInt(exp, 10, 64)
+	if err != nil {
+		return ErrInvalidToken
+	}
+	if now.After(time.Unix(unix, 0)) {
+		return ErrExpiredToken
+	}
+	return nil
+}
+
+func mac(secret []byte, section, slug, exp string) string {
+	h := hmac.New(sha256.New, secret)
+	h.Write([]byte(section + "/" + slug + "\n" + exp))
+	return base64.RawURLEncoding.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,60 @@
+package preview
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestVerify(t *testing.T) {
+	secret := []byte("secret")
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	valid := Sign(secret, "articles", "draft", now.Add(time.Hour))
+
+	// tamper flips the last character of the MAC
+	tamper := func(token string) string {
+		last := token[len(token)-1]
+		if last == 'A' {
+			return token[:len(token)-1] + "B"
+		}
+		return token[:len(token)-1] + "A"
+	}
+
+	tests := []struct {
+		name          string
+		secret        []byte
+		section, slug string
+		token         string
+		now           time.Time
+		want          error
+	}{
+		{"valid", secret, "articles", "draft", valid, now, nil},
+		{"valid until the expiry", secret, "articles", "draft", valid, now.Add(time.Hour), nil},
+		{"expired", secret, "articles", "draft", valid, now.Add(time.Hour + time.Second), ErrExpiredToken},
+		{"other section", secret, "fragments", "draft", valid, now, ErrInvalidToken},
+		{"other slug", secret, "articles", "other", valid, now, ErrInvalidToken},
+		{"wrong secret", []byte("other"), "articles", "draft", valid, now, ErrInvalidToken},
+		{"no secret", nil, "articles", "draft", valid, now, ErrInvalidToken},
+		{"tampered mac", secret, "articles", "draft", tamper(valid), now, ErrInvalidToken},
+		{"extended expiry", secret, "articles", "draft", "9999999999" + valid[strings.Index(valid, "."):], now, ErrInvalidToken},
+		{"empty", secret, "articles", "draft", "", now, ErrInvalidToken},
+		{"no separator", secret, "articles", "draft", strings.Replace(valid, ".", "", 1), now, ErrInvalidToken},
+		{"no mac", secret, "articles", "draft", strings.SplitAfter(valid, ".")[0], now, ErrInvalidToken},
+		{"trailing garbage", secret, "articles", "draft", Sign(secret, "articles", "draft", now) + "x", now, ErrInvalidToken},
+	}
+	for _, tt := range tests {
+		if err := Verify(tt.secret, tt.section, tt.slug, tt.token, tt.now); !errors.Is(err, tt.want) {
+			t.Errorf("%s: Verify = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestVerifyNonNumericExpiry(t *testing.T) {
+	// A MAC over a malformed expiry must still be rejected
+	secret := []byte("secret")
+	token := "soon." + mac(secret, "articles", "draft", "soon")
+	if err := Verify(secret, "articles", "draft", token, time.Now()); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("Verify = %v, want %v", err, ErrInvalidToken)
+	}
+}
@@ -3,6 +3,7 @@ package render
 import (
 	"bytes"
 	"html"
+	"math"
 	"strconv"
 	"strings"
 	"unicode"
@@ -22,12 +23,47 @@ type headingInfo struct {
 	ID    string
 }
 
+// Document is markdown parsed once, so its HTML, text stats and excerpt
+// all come from the same AST
+type Document struct {
+	source []byte
+	root   ast.Node
+
+	excerpt       *Document
+	excerptParsed bool
+}
+
+// Parse parses markdown with the extensions we render with
+func Parse(md string) *Document {
+	source := []byte(md)
+	return &Document{
+		source: source,
+		root:   newMarkdown().Parser().Parse(text.NewReader(source)),
+	}
+}
+
 // MarkdownToHTML converts Markdown to HTML and returns a Table of Contents HTML snippet.
 func MarkdownToHTML(md string) (string, string, error) {
+	return Parse(md).HTML()
+}
+
+// HTML renders the document and returns it with a Table of Contents HTML snippet
+func (d *Document) HTML() (string, string, error) {
 	var buf bytes.Buffer
-	source := []byte(md)
 
-	mdParser := goldmark.New(
+	// Collecting the headings also assigns their ids, so it comes first
+	toc := buildTOCHTML(d.root, d.source)
+
+	if err := newMarkdown().Renderer().Render(&buf, d.source, d.root); err != nil {
+		return "", "", err
+	}
+
+	return buf.String(), toc, nil
+}
+
+// newMarkdown returns goldmark configured with the extensions we render with
+func newMarkdown() goldmark.Markdown {
+	return goldmark.New(
 		goldmark.WithExtensions(
 			extension.GFM,
 			extension.Linkify,
@@ -47,17 +83,133 @@ func MarkdownToHTML(md string) (string, string, error) {
 			gmhtml.WithUnsafe(),
 		),
 	)
+}
 
-	reader := text.NewReader(source)
-	doc := mdParser.Parser().Parse(reader)
+// Reading speeds behind TextStats.ReadingTime
+const (
+	// wordsPerMinute is a typical adult reading speed for prose
+	wordsPerMinute = 230
 
-	toc := buildTOCHTML(doc, source)
+	// secondsPerCodeLine is how long a reader spends on each line of code
+	secondsPerCodeLine = 4
+)
 
-	if err := mdParser.Renderer().Render(&buf, source, doc); err != nil {
-		return "", "", err
+// TextStats is the readable content of a markdown document
+type TextStats struct {
+	Plain     string // prose and code without markup, one block per line
+	Lead      string // text of the first paragraph
+	Words     int    // words of prose, not counting code
+	CodeLines int    // non-blank lines of code blocks
+}
+
+// ReadingTime estimates the minutes it takes to read the document, rounded
+// up; prose and code are timed separately since code reads far slower
+func (t TextStats) ReadingTime() int {
+	if t.Words == 0 && t.CodeLines == 0 {
+		return 0
 	}
+	seconds := float64(t.Words)*60/wordsPerMinute + float64(t.CodeLines*secondsPerCodeLine)
+	return int(math.Ceil(seconds / 60))
+}
 
-	return buf.String(), toc, nil
+// Stats walks the AST collecting its plain text, a prose word count and the
+// number of code lines; raw HTML is skipped. Words are counted once over all
+// the prose, since emphasis and typographer quotes split a word across nodes.
+func (d *Document) Stats() TextStats {
+	source, doc := d.source, d.root
+
+	var b, prose strings.Builder
+	write := func(s string) {
+		b.WriteString(s)
+		prose.WriteString(s)
+	}
+	var stats TextStats
+	leadStart, leadEnd := -1, -1
+	ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		switch node := n.(type) {
+		case *ast.Paragraph:
+			if entering && leadStart < 0 {
+				leadStart = b.Len()
+			}
+			if !entering {
+				if leadEnd < 0 {
+					leadEnd = b.Len()
+				}
+				write("\n")
+			}
+		case *ast.Text:
+			if entering {
+				write(string(node.Segment.Value(source)))
+				if node.SoftLineBreak() || node.HardLineBreak() {
+					write(" ")
+				}
+			}
+		case *ast.String:
+			// Typographer substitutions are stored as HTML entities
+			if entering {
+				write(html.UnescapeString(string(node.Value)))
+			}
+		case *ast.FencedCodeBlock, *ast.CodeBlock:
+			if entering {
+				lines := n.Lines()
+				for i := 0; i < lines.Len(); i++ {
+					segment := lines.At(i)
+					line := segment.Value(source)
+					if len(bytes.TrimSpace(line)) > 0 {
+						stats.CodeLines++
+					}
+					b.Write(line)
+				}
+				prose.WriteString("\n")
+			}
+			return ast.WalkSkipChildren, nil
+		case *ast.HTMLBlock, *ast.RawHTML:
+			return ast.WalkSkipChildren, nil
+		default:
+			if !entering && n.Type() == ast.TypeBlock {
+				write("\n")
+			}
+		}
+		return ast.WalkContinue, nil
+	})
+
+	stats.Words = len(strings.Fields(prose.String()))
+	plain := b.String()
+	if leadStart >= 0 && leadEnd >= leadStart {
+		stats.Lead = strings.TrimSpace(plain[leadStart:leadEnd])
+	}
+	stats.Plain = strings.TrimSpace(plain)
+	return stats
+}
+
+// moreMarker is an HTML comment on a line of its own that ends a post's excerpt
+const moreMarker = "<!--more-->"
+
+// Excerpt returns the part of the document before a top-level <!--more-->
+// marker, parsed; ok is false when the document has none
+func (d *Document) Excerpt() (excerpt *Document, ok bool) {
+	if !d.excerptParsed {
+		d.excerptParsed = true
+		if md, ok := d.excerptSource(); ok {
+			d.excerpt = Parse(md)
+		}
+	}
+	return d.excerpt, d.excerpt != nil
+}
+
+// excerptSource returns the markdown before the <!--more--> marker
+func (d *Document) excerptSource() (string, bool) {
+	for n := d.root.FirstChild(); n != nil; n = n.NextSibling() {
+		block, isHTML := n.(*ast.HTMLBlock)
+		if !isHTML || block.Lines().Len() != 1 {
+			continue
+		}
+		line := block.Lines().At(0)
+		if string(bytes.TrimSpace(line.Value(d.source))) == moreMarker {
+			return strings.TrimSpace(string(d.source[:line.Start])), true
+		}
+	}
+	return "", false
 }
 
 func buildTOCHTML(doc ast.Node, source []byte) string {
@@ -192,6 +344,12 @@ func collectHeadings(doc ast.Node, source []byte) []headingInfo {
 	return headings
 }
 
+// Slugify lowercases text and joins its words with hyphens, dropping
+// anything but ASCII letters and digits; heading ids use the same rules
+func Slugify(text string) string {
+	return slugify(text)
+}
+
 func slugify(input string) string {
 	input = strings.ToLower(input)
 	var b strings.Builder
@@ -0,0 +1,36 @@
+package render
+
+import "testing"
+
+func TestStatsWords(t *testing.T) {
+	tests := []struct {
+		md    string
+		words int
+	}{
+		{"I don't think it's snake_case_ident here.", 6},
+		{"Hello **world** and foo**bar**baz.", 4},
+		{"one *two*\nthree", 3},
+		{"prose here\n\n```go\nfunc main() {}\n```\n\nand after", 4},
+		{"<div>raw html</div>\n\ntext", 1},
+	}
+	for _, tt := range tests {
+		if got := Parse(tt.md).Stats().Words; got != tt.words {
+			t.Errorf("Stats(%q).Words = %d, want %d", tt.md, got, tt.words)
+		}
+	}
+}
+
+func TestExcerpt(t *testing.T) {
+	doc := Parse("First *part*.\n\n<!--more-->\n\nThe rest.")
+	excerpt, ok := doc.Excerpt()
+	if !ok {
+		t.Fatal("Excerpt() found no marker")
+	}
+	if got := excerpt.Stats().Plain; got != "First part." {
+		t.Errorf("excerpt text = %q, want %q", got, "First part.")
+	}
+
+	if _, ok := Parse("No marker here.").Excerpt(); ok {
+		t.Error("Excerpt() found a marker in a document without one")
+	}
+}
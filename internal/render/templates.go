@@ -2,62 +2,145 @@ package render
 
 import (
 	"html/template"
+	"io/fs"
 	"net/http"
-	"path/filepath"
+	"net/url"
+	"strings"
 	"sync"
 	"time"
+
+	"journal/internal/config"
 )
 
 // TemplateCache holds parsed templates
 type TemplateCache struct {
 	templates map[string]*template.Template
+	fsys      fs.FS
+	site      Site
 	mu        sync.RWMutex
 }
 
+// Site is the journal-wide data every page sees as .Site
+type Site struct {
+	Title  string
+	Author string
+	Menu   []config.MenuItem
+
+	// Feeds reports whether feeds are served, which needs a base URL
+	Feeds bool
+}
+
 var cache *TemplateCache
 
-// InitTemplates parses all templates once at startup
-func InitTemplates() error {
+// liveReload injects the dev-mode reload script into every page
+var liveReload bool
+
+// EnableLiveReload makes base.html include the live-reload script
+func EnableLiveReload() {
+	liveReload = true
+}
+
+// staticExport leaves out what needs the server, such as search
+var staticExport bool
+
+// EnableStaticExport renders every page from now on for a static export
+func EnableStaticExport() {
+	staticExport = true
+}
+
+// templateFiles lists every page template; each is parsed together with base.html
+var templateFiles = []string{
+	"base.html",
+	"home.html",
+	"articles.html",
+	"article_detail.html",
+	"fragments.html",
+	"fragment_detail.html",
+	"shelf.html",
+	"shelf_detail.html",
+	"pixels.html",
+	"pixel_detail.html",
+	"about.html",
+	"search.html",
+	"tags.html",
+	"tag.html",
+	"archive.html",
+	"archive_period.html",
+	"series.html",
+	"not_found.html",
+}
+
+// InitTemplates parses all templates in fsys once at startup
+func InitTemplates(fsys fs.FS, site Site) error {
 	cache = &TemplateCache{
 		templates: make(map[string]*template.Template),
+		fsys:      fsys,
+		site:      site,
 	}
 
-	templateFiles := []string{
-		"base.html",
-		"home.html",
-		"articles.html",
-		"article_detail.html",
-		"fragments.html",
-		"fragment_detail.html",
-		"shelf.html",
-		"shelf_detail.html",
-		"pixels.html",
-		"about.html",
+	for _, tmpl := range templateFiles {
+		ts, err := parsePage(tmpl)
+		if err != nil {
+			return err
+		}
+
+		cache.templates[tmpl] = ts
 	}
 
-	basePath := filepath.Join("internal", "templates", "base.html")
+	return nil
+}
 
-	for _, tmpl := range templateFiles {
-		pagePath := filepath.Join("internal", "templates", tmpl)
+// ReloadTemplate re-parses a changed template and swaps it into the cache.
+// A change to base.html re-parses every page since they all embed it.
+func ReloadTemplate(name string) error {
+	pages := []string{name}
+	if name == "base.html" {
+		pages = templateFiles
+	}
 
-		// Parse base + page template
-		ts, err := template.ParseFiles(basePath, pagePath)
+	parsed := make(map[string]*template.Template, len(pages))
+	for _, tmpl := range pages {
+		ts, err := parsePage(tmpl)
 		if err != nil {
 			return err
 		}
+		parsed[tmpl] = ts
+	}
 
+	cache.mu.Lock()
+	for tmpl, ts := range parsed {
 		cache.templates[tmpl] = ts
 	}
+	cache.mu.Unlock()
 
 	return nil
 }
 
+// funcs are the helpers every template can call
+var funcs = template.FuncMap{
+	"tagpath": EscapeTag,
+}
+
+// EscapeTag escapes tag for a tag query in a URL path or query string, so
+// the spaces, "+" and "," it may contain are not read as operators
+func EscapeTag(tag string) string {
+	return strings.ReplaceAll(url.QueryEscape(tag), "+", "%20")
+}
+
+// parsePage parses base.html together with a page template
+func parsePage(tmpl string) (*template.Template, error) {
+	return template.New("base.html").Funcs(funcs).ParseFS(cache.fsys, "base.html", tmpl)
+}
+
 // Render executes a cached template
 func Render(w http.ResponseWriter, tmpl string, data map[string]any) error {
 	if data == nil {
 		data = map[string]any{}
 	}
 	data["Year"] = time.Now().Year()
+	data["LiveReload"] = liveReload
+	data["Static"] = staticExport
+	data["Site"] = cache.site
 
 	cache.mu.RLock()
 	ts, ok := cache.templates[tmpl]
@@ -73,10 +156,7 @@ func Render(w http.ResponseWriter, tmpl string, data map[string]any) error {
 
 // renderFallback is a safety net if template not in cache
 func renderFallback(w http.ResponseWriter, tmpl string, data map[string]any) error {
-	basePath := filepath.Join("internal", "templates", "base.html")
-	pagePath := filepath.Join("internal", "templates", tmpl)
-
-	ts, err := template.ParseFiles(basePath, pagePath)
+	ts, err := parsePage(tmpl)
 	if err != nil {
 		return err
 	}
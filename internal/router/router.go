@@ -2,6 +2,7 @@ package router
 
 import (
 	"net/http"
+	"net/url"
 	"strings"
 )
 
@@ -28,3 +29,14 @@ func ExtractPathParam(r *http.Request, prefix string) (string, bool) {
 	return slug, true
 }
 
+// RawQueryParam returns the first value of key in u's query string without
+// unescaping it, for values whose syntax tells escaped characters apart
+// from literal ones
+func RawQueryParam(u *url.URL, key string) string {
+	for _, part := range strings.Split(u.RawQuery, "&") {
+		if k, v, _ := strings.Cut(part, "="); k == key {
+			return v
+		}
+	}
+	return ""
+}
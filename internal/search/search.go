@@ -0,0 +1,272 @@
+package search
+
+import (
+	"html"
+	"html/template"
+	"math"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// BM25 tuning: k1 controls term-frequency saturation, b length normalisation
+const (
+	k1 = 1.2
+	b  = 0.75
+
+	// titleWeight counts a title term as this many body occurrences
+	titleWeight = 3
+
+	// snippetWords is how many words of context a snippet shows
+	snippetWords = 30
+)
+
+// Document is one searchable page
+type Document struct {
+	Section string
+	Slug    string
+	Title   string
+	URL     string
+	Text    string
+	Date    time.Time
+}
+
+// Result is a ranked match with a highlighted excerpt
+type Result struct {
+	Document
+	Score   float64
+	Snippet template.HTML
+}
+
+type posting struct {
+	doc  int
+	freq float64
+}
+
+// Index is an inverted index over stemmed terms, ranked with BM25
+type Index struct {
+	docs      []Document
+	postings  map[string][]posting
+	lengths   []float64
+	avgLength float64
+}
+
+// NewIndex tokenizes every document and builds the inverted index
+func NewIndex(docs []Document) *Index {
+	idx := &Index{
+		docs:     docs,
+		postings: make(map[string][]posting),
+		lengths:  make([]float64, len(docs)),
+	}
+
+	var total float64
+	for i, doc := range docs {
+		freqs := make(map[string]float64)
+		for _, term := range Tokenize(doc.Title) {
+			freqs[term] += titleWeight
+		}
+		for _, term := range Tokenize(doc.Text) {
+			freqs[term]++
+		}
+
+		var length float64
+		for term, freq := range freqs {
+			idx.postings[term] = append(idx.postings[term], posting{doc: i, freq: freq})
+			length += freq
+		}
+		idx.lengths[i] = length
+		total += length
+	}
+	if len(docs) > 0 {
+		idx.avgLength = total / float64(len(docs))
+	}
+
+	return idx
+}
+
+// Search returns up to limit documents matching any query term, best first
+func (idx *Index) Search(query string, limit int) []Result {
+	terms := unique(Tokenize(query))
+	if len(terms) == 0 || len(idx.docs) == 0 {
+		return nil
+	}
+
+	n := float64(len(idx.docs))
+	scores := make(map[int]float64)
+	for _, term := range terms {
+		postings := idx.postings[term]
+		if len(postings) == 0 {
+			continue
+		}
+		df := float64(len(postings))
+		idf := math.Log(1 + (n-df+0.5)/(df+0.5))
+		for _, p := range postings {
+			norm := 1 - b + b*idx.lengths[p.doc]/idx.avgLength
+			scores[p.doc] += idf * p.freq * (k1 + 1) / (p.freq + k1*norm)
+		}
+	}
+
+	results := make([]Result, 0, len(scores))
+	for doc, score := range scores {
+		results = append(results, Result{Document: idx.docs[doc], Score: score})
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].Date.After(results[j].Date)
+	})
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+
+	termSet := make(map[string]bool, len(terms))
+	for _, term := range terms {
+		termSet[term] = true
+	}
+	for i := range results {
+		results[i].Snippet = snippet(results[i].Text, termSet)
+	}
+
+	return results
+}
+
+// Tokenize lowercases text, splits it into words, drops stop words and
+// stems what is left
+func Tokenize(text string) []string {
+	var terms []string
+	for _, w := range words(text) {
+		word := strings.ToLower(text[w.start:w.end])
+		if len(word) < 2 || stopWords[word] {
+			continue
+		}
+		terms = append(terms, Stem(word))
+	}
+	return terms
+}
+
+type span struct {
+	start, end int
+}
+
+// words returns the byte spans of each run of letters and digits in text
+func words(text string) []span {
+	var spans []span
+	start := -1
+	for i, r := range text {
+		isWord := unicode.IsLetter(r) || unicode.IsDigit(r)
+		switch {
+		case isWord && start < 0:
+			start = i
+		case !isWord && start >= 0:
+			spans = append(spans, span{start, i})
+			start = -1
+		}
+	}
+	if start >= 0 {
+		spans = append(spans, span{start, len(text)})
+	}
+	return spans
+}
+
+// snippet picks the window of text with the most query terms and wraps
+// each matching word in <mark>
+func snippet(text string, terms map[string]bool) template.HTML {
+	spans := words(text)
+	if len(spans) == 0 {
+		return ""
+	}
+
+	matched := make([]bool, len(spans))
+	for i, w := range spans {
+		matched[i] = terms[Stem(strings.ToLower(text[w.start:w.end]))]
+	}
+
+	// Slide a fixed window over the words, keeping the one with most hits
+	best, hits := 0, 0
+	for i := 0; i < len(spans) && i < snippetWords; i++ {
+		if matched[i] {
+			hits++
+		}
+	}
+	bestHits := hits
+	for start := 1; start+snippetWords <= len(spans); start++ {
+		if matched[start-1] {
+			hits--
+		}
+		if matched[start+snippetWords-1] {
+			hits++
+		}
+		if hits > bestHits {
+			best, bestHits = start, hits
+		}
+	}
+	end := best + snippetWords
+	if end > len(spans) {
+		end = len(spans)
+	}
+
+	var out strings.Builder
+	if best > 0 {
+		out.WriteString("… ")
+	}
+	for i := best; i < end; i++ {
+		w := spans[i]
+		if i > best {
+			out.WriteString(html.EscapeString(collapseSpace(text[spans[i-1].end:w.start])))
+		}
+		word := html.EscapeString(text[w.start:w.end])
+		if matched[i] {
+			out.WriteString("<mark>" + word + "</mark>")
+		} else {
+			out.WriteString(word)
+		}
+	}
+	if end < len(spans) {
+		out.WriteString(" …")
+	}
+
+	return template.HTML(out.String())
+}
+
+// collapseSpace turns each run of whitespace (including newlines) into one space
+func collapseSpace(s string) string {
+	var out strings.Builder
+	space := false
+	for _, r := range s {
+		if unicode.IsSpace(r) {
+			if !space {
+				out.WriteByte(' ')
+			}
+			space = true
+			continue
+		}
+		space = false
+		out.WriteRune(r)
+	}
+	return out.String()
+}
+
+func unique(terms []string) []string {
+	seen := make(map[string]bool, len(terms))
+	var out []string
+	for _, t := range terms {
+		if !seen[t] {
+			seen[t] = true
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+// stopWords are too common to help ranking
+var stopWords = map[string]bool{
+	"a": true, "an": true, "and": true, "are": true, "as": true, "at": true,
+	"be": true, "but": true, "by": true, "for": true, "from": true, "has": true,
+	"have": true, "if": true, "in": true, "into": true, "is": true, "it": true,
+	"its": true, "of": true, "on": true, "or": true, "that": true, "the": true,
+	"their": true, "then": true, "there": true, "these": true, "they": true,
+	"this": true, "to": true, "was": true, "we": true, "were": true, "what": true,
+	"when": true, "which": true, "will": true, "with": true, "you": true,
+}
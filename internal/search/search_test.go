@@ -0,0 +1,120 @@
+package search
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSearchRanking(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2025, 1, d, 0, 0, 0, 0, time.UTC) }
+	tests := []struct {
+		name  string
+		docs  []Document
+		query string
+		want  []string // slugs, best first
+	}{
+		{
+			name: "title outweighs one body mention",
+			docs: []Document{
+				{Slug: "body", Title: "Notes", Text: "kafka once among other words here"},
+				{Slug: "title", Title: "Kafka", Text: "other words here and more words"},
+			},
+			query: "kafka",
+			want:  []string{"title", "body"},
+		},
+		{
+			name: "term frequency saturates but still counts",
+			docs: []Document{
+				{Slug: "once", Text: "retry then give up on the queue"},
+				{Slug: "thrice", Text: "retry retry retry on the queue"},
+			},
+			query: "retry",
+			want:  []string{"thrice", "once"},
+		},
+		{
+			name: "shorter document wins at equal frequency",
+			docs: []Document{
+				{Slug: "long", Text: "cache plus many many other unrelated filler words in this text"},
+				{Slug: "short", Text: "cache plus little"},
+			},
+			query: "cache",
+			want:  []string{"short", "long"},
+		},
+		{
+			name: "rare term outweighs a common one",
+			docs: []Document{
+				{Slug: "common", Text: "database database"},
+				{Slug: "rare", Text: "database sharding"},
+				{Slug: "filler1", Text: "database notes", Date: day(2)},
+				{Slug: "filler2", Text: "database ideas", Date: day(1)},
+			},
+			query: "database sharding",
+			want:  []string{"rare", "common", "filler1", "filler2"},
+		},
+		{
+			name: "stems match across word forms",
+			docs: []Document{
+				{Slug: "a", Text: "we retried the job"},
+				{Slug: "b", Text: "nothing relevant"},
+			},
+			query: "retrying",
+			want:  []string{"a"},
+		},
+		{
+			name: "ties go to the newest",
+			docs: []Document{
+				{Slug: "old", Text: "pause", Date: day(1)},
+				{Slug: "new", Text: "pause", Date: day(2)},
+			},
+			query: "pause",
+			want:  []string{"new", "old"},
+		},
+		{
+			name:  "stop words alone match nothing",
+			docs:  []Document{{Slug: "a", Text: "the and of a"}},
+			query: "the and",
+			want:  nil,
+		},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, r := range NewIndex(tt.docs).Search(tt.query, 0) {
+			got = append(got, r.Slug)
+		}
+		if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("%s: Search(%q) = %q, want %q", tt.name, tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestSearchScore(t *testing.T) {
+	// One of two documents holds the term once; both are two terms long,
+	// so the length norm is 1 and the score is idf * (k1+1) / (1+k1)
+	idx := NewIndex([]Document{
+		{Slug: "a", Text: "alpha beta"},
+		{Slug: "b", Text: "gamma delta"},
+	})
+	results := idx.Search("alpha", 0)
+	if len(results) != 1 {
+		t.Fatalf("Search returned %d results, want 1", len(results))
+	}
+	want := math.Log(1 + (2-1+0.5)/(1+0.5))
+	if math.Abs(results[0].Score-want) > 1e-9 {
+		t.Errorf("score = %v, want %v", results[0].Score, want)
+	}
+	if got := string(results[0].Snippet); got != "<mark>alpha</mark> beta" {
+		t.Errorf("snippet = %q, want %q", got, "<mark>alpha</mark> beta")
+	}
+}
+
+func TestSearchLimit(t *testing.T) {
+	docs := make([]Document, 5)
+	for i := range docs {
+		docs[i] = Document{Slug: string(rune('a' + i)), Text: "term"}
+	}
+	if got := len(NewIndex(docs).Search("term", 2)); got != 2 {
+		t.Errorf("Search with limit 2 returned %d results", got)
+	}
+}
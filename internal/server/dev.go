@@ -0,0 +1,58 @@
+package server
+
+import (
+	"context"
+	"log"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"journal/internal/content"
+	"journal/internal/render"
+	"journal/internal/watch"
+)
+
+// watch polls the content and template trees and reloads whatever changed,
+// then tells open browser tabs which pages to refresh
+func (s *Server) watch(ctx context.Context) {
+	contentDir := filepath.ToSlash(filepath.Clean(s.cfg.Dirs.Content))
+	templatesDir := filepath.ToSlash(filepath.Clean(s.cfg.Dirs.Templates))
+	stylesheet := filepath.ToSlash(filepath.Join(s.cfg.Dirs.Static, "css", "styles.css"))
+	w := watch.New(500*time.Millisecond, contentDir, templatesDir, stylesheet)
+	log.Printf("Dev mode: watching %s, %s and %s", contentDir, templatesDir, stylesheet)
+
+	w.Run(ctx, func(path string) {
+		path = filepath.ToSlash(path)
+
+		switch {
+		case strings.HasPrefix(path, templatesDir+"/"):
+			if filepath.Ext(path) != ".html" {
+				return
+			}
+			if err := render.ReloadTemplate(filepath.Base(path)); err != nil {
+				log.Printf("Reload %s: %v", path, err)
+				return
+			}
+			log.Printf("Reloaded template %s", path)
+			s.reload.publish([]string{"*"})
+
+		case strings.HasPrefix(path, contentDir+"/"):
+			if filepath.Ext(path) != ".md" {
+				return
+			}
+			before := viewContent(contentDir, path)
+			changed, err := content.Current().Reload(path)
+			if err != nil {
+				log.Printf("Reload %s: %v", path, err)
+				return
+			}
+			if changed {
+				log.Printf("Reloaded content %s", path)
+				s.reload.publish(pagesForChange(before, viewContent(contentDir, path)))
+			}
+
+		case path == stylesheet:
+			s.reload.publish([]string{"*"})
+		}
+	})
+}
@@ -0,0 +1,388 @@
+package server
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"journal/internal/content"
+	"journal/internal/handlers"
+	"journal/internal/render"
+	"journal/internal/router"
+)
+
+// manifestFile records the hash of every file Export wrote, so the next
+// build skips pages whose bytes did not change and removes stale ones
+const manifestFile = ".journal-build.json"
+
+// exportSkip lists routes that make no sense without the server: signed
+// previews, the dev reload stream and the query-driven search page and API.
+// Static files are copied rather than rendered.
+var exportSkip = map[string]bool{
+	"/preview/":     true,
+	"/__livereload": true,
+	"/search":       true,
+	"/search.json":  true,
+	"/static/":      true,
+}
+
+// ExportStats counts what Export did with each output file
+type ExportStats struct {
+	Written   int
+	Unchanged int
+	Removed   int
+}
+
+// Export renders every registered route, every entry and every page linked
+// from them through the same handlers a request would reach, and writes the
+// result to out as a static site: pages become dir/index.html, ?page=N
+// listings become dir/page/N/index.html and links are rewritten to match.
+// The static directory is copied alongside. Files whose content hash matches the
+// previous build are left untouched.
+func (s *Server) Export(out string) (ExportStats, error) {
+	if s.cfg.BaseURL == "" {
+		return ExportStats{}, errors.New("export needs a base URL for absolute links")
+	}
+	base := strings.TrimRight(s.cfg.BaseURL, "/")
+	render.EnableStaticExport()
+	if err := os.MkdirAll(out, 0o755); err != nil {
+		return ExportStats{}, err
+	}
+
+	e := &exporter{
+		server: s,
+		base:   base,
+		out:    out,
+		old:    readManifest(out),
+		hashes: make(map[string]string),
+		seen:   make(map[string]bool),
+	}
+	e.absLink = regexp.MustCompile(regexp.QuoteMeta(base) + `(/[^\s"'<>&\\]*)?`)
+
+	for _, page := range s.exportSeeds() {
+		e.enqueue(page)
+	}
+	for len(e.queue) > 0 {
+		page := e.queue[0]
+		e.queue = e.queue[1:]
+		if err := e.render(page); err != nil {
+			return e.stats, err
+		}
+	}
+
+	if err := e.renderNotFound(); err != nil {
+		return e.stats, err
+	}
+	if err := e.copyStatic(s.static); err != nil {
+		return e.stats, err
+	}
+	if err := e.removeStale(); err != nil {
+		return e.stats, err
+	}
+	return e.stats, writeManifest(out, e.hashes)
+}
+
+// exportSeeds expands the registered routes into concrete pages: fixed
+// routes as they are, section subtrees into one page per entry and tag
+// patterns into one page per tag. Everything else, such as archive
+// periods, series and further listing pages, is found by following links.
+func (s *Server) exportSeeds() []string {
+	store := content.Current()
+	entries := map[string][]string{}
+	for _, a := range store.Articles() {
+		entries["/articles/"] = append(entries["/articles/"], a.Slug)
+	}
+	for _, f := range store.Fragments() {
+		entries["/fragments/"] = append(entries["/fragments/"], f.Slug)
+	}
+	for _, item := range store.ShelfItems() {
+		entries["/shelf/"] = append(entries["/shelf/"], item.Slug)
+	}
+	for _, p := range store.Pixels() {
+		entries["/pixels/"] = append(entries["/pixels/"], p.Slug)
+	}
+
+	var pages []string
+	for _, pattern := range s.routes {
+		switch {
+		case exportSkip[pattern]:
+		case strings.Contains(pattern, "{query}"):
+			for _, tag := range store.Tags() {
+				pages = append(pages, strings.Replace(pattern, "{query}", render.EscapeTag(tag.Name), 1))
+			}
+		case pattern != "/" && strings.HasSuffix(pattern, "/"):
+			for _, slug := range entries[pattern] {
+				pages = append(pages, pattern+slug)
+			}
+		default:
+			pages = append(pages, pattern)
+		}
+	}
+	return pages
+}
+
+// exporter is the state of one Export run
+type exporter struct {
+	server  *Server
+	base    string
+	out     string
+	absLink *regexp.Regexp
+
+	queue []string
+	seen  map[string]bool
+
+	old    map[string]string // manifest of the previous build
+	hashes map[string]string // manifest of this one
+	stats  ExportStats
+}
+
+// enqueue schedules a page (a path, optionally with ?page=) once
+func (e *exporter) enqueue(page string) {
+	if !e.seen[page] {
+		e.seen[page] = true
+		e.queue = append(e.queue, page)
+	}
+}
+
+// render serves page through the mux, queues the pages it links to and
+// writes it with its links rewritten
+func (e *exporter) render(page string) error {
+	rec := e.serve(page)
+	if rec.Code != http.StatusOK {
+		log.Printf("export: skipping %s: %d %s", page, rec.Code, http.StatusText(rec.Code))
+		return nil
+	}
+
+	body := rec.Body.Bytes()
+	html := strings.HasPrefix(rec.Header().Get("Content-Type"), "text/html")
+	for _, link := range e.links(body, html) {
+		e.enqueue(link)
+	}
+	return e.write(outputPath(page), e.rewrite(body, html))
+}
+
+// renderNotFound writes the site's not-found page as 404.html, which
+// static hosts serve for missing keys
+func (e *exporter) renderNotFound() error {
+	rec := httptest.NewRecorder()
+	handlers.HandleNotFound(rec, httptest.NewRequest(http.MethodGet, e.base+"/404", nil))
+	return e.write("404.html", rec.Body.Bytes())
+}
+
+func (e *exporter) serve(page string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, e.base+page, nil)
+	rec := httptest.NewRecorder()
+	e.server.mux.ServeHTTP(rec, req)
+	return rec
+}
+
+// attrLink finds root-relative link targets in HTML attributes
+var attrLink = regexp.MustCompile(`(href|src|action)="(/[^"]*)"`)
+
+// links returns the pages body links to that Export should render: site
+// paths without a query, or with only ?page=
+func (e *exporter) links(body []byte, html bool) []string {
+	var targets []string
+	if html {
+		for _, m := range attrLink.FindAllSubmatch(body, -1) {
+			targets = append(targets, string(m[2]))
+		}
+	}
+	for _, m := range e.absLink.FindAllSubmatch(body, -1) {
+		targets = append(targets, "/"+strings.TrimPrefix(string(m[1]), "/"))
+	}
+
+	var pages []string
+	for _, target := range targets {
+		u, err := url.Parse(strings.ReplaceAll(target, "&amp;", "&"))
+		if err != nil || strings.HasPrefix(target, "//") || strings.HasPrefix(u.Path, "/static/") {
+			continue
+		}
+		page := u.EscapedPath()
+		if tags, ok := tagsQuery(u); ok {
+			page = tagsPage(tags)
+		} else if n := u.Query().Get("page"); n != "" && len(u.Query()) == 1 {
+			page += "?page=" + n
+		} else if u.RawQuery != "" {
+			continue
+		}
+		if !exportSkip[page] && !strings.HasPrefix(page, "/preview/") {
+			pages = append(pages, page)
+		}
+	}
+	return pages
+}
+
+// rewrite points links at the exported files
+func (e *exporter) rewrite(body []byte, html bool) []byte {
+	if html {
+		body = attrLink.ReplaceAllFunc(body, func(m []byte) []byte {
+			sub := attrLink.FindSubmatch(m)
+			return []byte(fmt.Sprintf(`%s="%s"`, sub[1], publicURL(string(sub[2]))))
+		})
+	}
+	return e.absLink.ReplaceAllFunc(body, func(m []byte) []byte {
+		return []byte(e.base + publicURL("/"+strings.TrimPrefix(string(m[len(e.base):]), "/")))
+	})
+}
+
+// publicURL maps a site link to where its page lives in the export:
+// /articles becomes /articles/, /articles?page=2 becomes
+// /articles/page/2/, /articles?tags=go becomes the tag page /tags/go/ and
+// files with an extension keep their path
+func publicURL(link string) string {
+	if strings.HasPrefix(link, "//") {
+		return link
+	}
+	u, err := url.Parse(strings.ReplaceAll(link, "&amp;", "&"))
+	if err != nil {
+		return link
+	}
+	p := u.EscapedPath()
+	query := u.Query()
+	if tags, ok := tagsQuery(u); ok {
+		p = tagsPage(tags)
+	} else if n := query.Get("page"); n != "" && len(query) == 1 {
+		p = strings.TrimSuffix(p, "/") + "/page/" + n
+	} else if u.RawQuery != "" {
+		return link
+	}
+	if path.Ext(p) == "" && !strings.HasSuffix(p, "/") {
+		p += "/"
+	}
+	if u.Fragment != "" {
+		p += "#" + u.Fragment
+	}
+	return p
+}
+
+// tagsQuery returns the filter of a ?tags= link, which a static host
+// cannot apply; the tag page with the same query lists the same entries
+func tagsQuery(u *url.URL) (string, bool) {
+	tags := router.RawQueryParam(u, "tags")
+	return tags, tags != "" && len(u.Query()) == 1
+}
+
+// tagsPage is the page for a tag query, in the escaped syntax of ?tags=
+func tagsPage(tags string) string {
+	return "/tags/" + content.ParseTagQuery(tags).Escaped()
+}
+
+// outputPath is the file, relative to the export root, holding page
+func outputPath(page string) string {
+	p := strings.TrimPrefix(publicURL(page), "/")
+	if unescaped, err := url.PathUnescape(p); err == nil {
+		p = unescaped
+	}
+	if p == "" || strings.HasSuffix(p, "/") {
+		p += "index.html"
+	}
+	return p
+}
+
+// write stores data at rel under out unless the previous build wrote the
+// same bytes there
+func (e *exporter) write(rel string, data []byte) error {
+	sum := sha256.Sum256(data)
+	hash := hex.EncodeToString(sum[:])
+	e.hashes[rel] = hash
+
+	file := filepath.Join(e.out, filepath.FromSlash(rel))
+	if e.old[rel] == hash {
+		if _, err := os.Stat(file); err == nil {
+			e.stats.Unchanged++
+			return nil
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(file, data, 0o644); err != nil {
+		return err
+	}
+	e.stats.Written++
+	return nil
+}
+
+// staticSources are the Tailwind sources compiled into css/styles.css;
+// no page loads them
+var staticSources = map[string]bool{
+	"css/input.css":    true,
+	"css/markdown.css": true,
+}
+
+// copyStatic copies the static files, less their build sources, into the
+// export under /static/
+func (e *exporter) copyStatic(static fs.FS) error {
+	return fs.WalkDir(static, ".", func(file string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || staticSources[file] {
+			return err
+		}
+		data, err := fs.ReadFile(static, file)
+		if err != nil {
+			return err
+		}
+		return e.write(path.Join("static", file), data)
+	})
+}
+
+// removeStale deletes files the previous build wrote that this one did not,
+// leaving anything else in out alone
+func (e *exporter) removeStale() error {
+	var stale []string
+	for rel := range e.old {
+		if _, ok := e.hashes[rel]; !ok {
+			stale = append(stale, rel)
+		}
+	}
+	sort.Strings(stale)
+	for _, rel := range stale {
+		file := filepath.Join(e.out, filepath.FromSlash(rel))
+		if err := os.Remove(file); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		e.stats.Removed++
+
+		// Drop directories left empty, such as a listing page that is gone
+		for dir := filepath.Dir(file); dir != filepath.Clean(e.out); dir = filepath.Dir(dir) {
+			if os.Remove(dir) != nil {
+				break
+			}
+		}
+	}
+	return nil
+}
+
+func readManifest(out string) map[string]string {
+	hashes := make(map[string]string)
+	data, err := os.ReadFile(filepath.Join(out, manifestFile))
+	if err == nil {
+		json.Unmarshal(data, &hashes)
+	}
+	return hashes
+}
+
+func writeManifest(out string, hashes map[string]string) error {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(hashes); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(out, manifestFile), buf.Bytes(), 0o644)
+}
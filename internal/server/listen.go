@@ -0,0 +1,73 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// listenFdsStart is the first descriptor systemd passes, after stdin,
+// stdout and stderr
+const listenFdsStart = 3
+
+// listen returns the socket systemd passed the server, if any, so restarts
+// never refuse a connection; otherwise it binds addr, a Unix socket for
+// unix:/path/to.sock and TCP for anything else
+func listen(addr string) (net.Listener, error) {
+	if l, err := systemdListener(); l != nil || err != nil {
+		return l, err
+	}
+
+	if path, ok := strings.CutPrefix(addr, "unix:"); ok {
+		// A socket left behind by a server that was killed blocks the bind;
+		// one a live server still accepts on is not ours to take
+		if info, err := os.Lstat(path); err == nil && info.Mode().Type() == fs.ModeSocket {
+			conn, err := net.Dial("unix", path)
+			if err == nil {
+				conn.Close()
+				return nil, fmt.Errorf("listen unix %s: %w", path, syscall.EADDRINUSE)
+			}
+			if !errors.Is(err, syscall.ECONNREFUSED) {
+				return nil, err
+			}
+			if err := os.Remove(path); err != nil {
+				return nil, err
+			}
+		}
+		return net.Listen("unix", path)
+	}
+	return net.Listen("tcp", addr)
+}
+
+// systemdListener returns the socket inherited through LISTEN_FDS, or nil
+// when the server was not socket-activated
+func systemdListener() (net.Listener, error) {
+	pid, err := strconv.Atoi(os.Getenv("LISTEN_PID"))
+	if err != nil || pid != os.Getpid() {
+		return nil, nil
+	}
+	fds := os.Getenv("LISTEN_FDS")
+	n, err := strconv.Atoi(fds)
+
+	// Processes we start must not take the sockets for their own
+	os.Unsetenv("LISTEN_PID")
+	os.Unsetenv("LISTEN_FDS")
+	os.Unsetenv("LISTEN_FDNAMES")
+
+	if err != nil || n != 1 {
+		return nil, fmt.Errorf("systemd passed LISTEN_FDS=%q; want exactly one socket", fds)
+	}
+
+	f := os.NewFile(listenFdsStart, "LISTEN_FDS")
+	defer f.Close()
+	l, err := net.FileListener(f)
+	if err != nil {
+		return nil, fmt.Errorf("systemd socket: %w", err)
+	}
+	return l, nil
+}
@@ -0,0 +1,188 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"journal/internal/content"
+	"journal/internal/models"
+	"journal/internal/render"
+)
+
+// liveReload fans out change notifications to open browser tabs over SSE
+type liveReload struct {
+	mu      sync.Mutex
+	clients map[chan []string]struct{}
+
+	done      chan struct{} // closed when the server shuts down
+	closeOnce sync.Once
+}
+
+func newLiveReload() *liveReload {
+	return &liveReload{
+		clients: make(map[chan []string]struct{}),
+		done:    make(chan struct{}),
+	}
+}
+
+// close ends every open stream so a graceful shutdown need not wait on them
+func (l *liveReload) close() {
+	l.closeOnce.Do(func() { close(l.done) })
+}
+
+// publish tells every connected tab which pages changed; "*" means all of them
+func (l *liveReload) publish(pages []string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for ch := range l.clients {
+		select {
+		case ch <- pages:
+		default:
+			// Slow client: it will catch up on the next change
+		}
+	}
+}
+
+func (l *liveReload) subscribe() chan []string {
+	ch := make(chan []string, 1)
+	l.mu.Lock()
+	l.clients[ch] = struct{}{}
+	l.mu.Unlock()
+	return ch
+}
+
+func (l *liveReload) unsubscribe(ch chan []string) {
+	l.mu.Lock()
+	delete(l.clients, ch)
+	l.mu.Unlock()
+}
+
+// ServeHTTP streams "reload" events until the browser disconnects
+func (l *liveReload) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	rc := http.NewResponseController(w)
+	// The stream outlives any write timeout the server sets
+	if err := rc.SetWriteDeadline(time.Time{}); err != nil {
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	if err := rc.Flush(); err != nil {
+		return
+	}
+
+	ch := l.subscribe()
+	defer l.unsubscribe(ch)
+
+	ping := time.NewTicker(30 * time.Second)
+	defer ping.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-l.done:
+			return
+		case pages := <-ch:
+			data, _ := json.Marshal(pages)
+			fmt.Fprintf(w, "event: reload\ndata: %s\n\n", data)
+		case <-ping.C:
+			fmt.Fprint(w, ": ping\n\n")
+		}
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+// entryView is what the entry in a content file shows across the site: the
+// pages that render or list it, and the fields other entries' pages show too
+// (in related, series and prev/next boxes)
+type entryView struct {
+	pages []string
+	shown string
+}
+
+// viewContent looks up the entry in a file under contentDir as the store
+// holds it now
+// Example: "internal/content/articles/my-post.md" -> "/articles/my-post",
+// "/articles", "/", its archive year and month, tag and series pages
+func viewContent(contentDir, path string) entryView {
+	rel := strings.TrimPrefix(path, contentDir+"/")
+	section, _, _ := strings.Cut(rel, "/")
+	slug := strings.TrimSuffix(filepath.Base(rel), ".md")
+	store := content.Current()
+
+	var title, series string
+	var date time.Time
+	var tags []string
+	var found bool
+	switch section {
+	case "articles":
+		var a models.Article
+		if a, found = store.Article(slug); found {
+			title, date, tags, series = a.Title, a.Date, a.Tags, a.Series
+		}
+	case "fragments":
+		var f models.Fragment
+		if f, found = store.Fragment(slug); found {
+			title, date, tags = f.Title, f.Date, f.Tags
+		}
+	case "shelf":
+		var item models.ShelfItem
+		if item, found = store.ShelfItem(slug); found {
+			title, date, tags = item.Title, item.Date, item.Tags
+		}
+	case "pixels":
+		var p models.Pixel
+		if p, found = store.Pixel(slug); found {
+			title, date = p.Title, p.Date
+		}
+	case "about":
+		return entryView{pages: []string{"/about"}}
+	default:
+		return entryView{}
+	}
+
+	v := entryView{pages: []string{"/" + section + "/" + slug, "/" + section}}
+	if !found {
+		return v
+	}
+	v.shown = fmt.Sprint(title, date, tags, series)
+	v.pages = append(v.pages, "/", "/archive",
+		fmt.Sprintf("/archive/%d", date.Year()),
+		fmt.Sprintf("/archive/%d/%02d", date.Year(), int(date.Month())))
+	for _, tag := range tags {
+		v.pages = append(v.pages, "/tags/"+render.EscapeTag(tag))
+	}
+	if series != "" {
+		v.pages = append(v.pages, "/series/"+render.Slugify(series))
+	}
+	return v
+}
+
+// pagesForChange returns the pages to reload after an entry changed from
+// before to after: every page either version appeared on, or all of them
+// when what other entries' pages show of it changed
+func pagesForChange(before, after entryView) []string {
+	if before.shown != after.shown {
+		return []string{"*"}
+	}
+	seen := make(map[string]bool)
+	var pages []string
+	for _, page := range append(before.pages, after.pages...) {
+		if !seen[page] {
+			seen[page] = true
+			pages = append(pages, page)
+		}
+	}
+	return pages
+}
@@ -1,56 +1,259 @@
 package server
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
 	"log"
 	"net/http"
+	"os"
+	"path"
+	"path/filepath"
 
+	"journal/internal/config"
+	"journal/internal/content"
 	"journal/internal/handlers"
 	"journal/internal/middleware"
 	"journal/internal/render"
 )
 
 type Server struct {
-	mux *http.ServeMux
+	mux    *http.ServeMux
+	routes []string // patterns in registration order, for Export
+	cfg    config.Config
+	opts   Options
+	static fs.FS
+	reload *liveReload
 }
 
-// New creates and configures the HTTP server
-func New() (*Server, error) {
+// Options tunes how the server behaves
+type Options struct {
+	// Dev watches content and templates, reloads them on change and
+	// live-reloads open browser tabs
+	Dev bool
+
+	// Drafts serves drafts and future-dated entries as if published
+	Drafts bool
+
+	// PreviewSecret signs /preview links; previews are disabled when empty
+	PreviewSecret []byte
+
+	// Robots is the robots.txt body; a default is served when empty
+	Robots string
+
+	// Files holds the templates, content and static files at the paths
+	// the configuration names, such as an embed.FS of the repository; when
+	// nil those directories are read from disk, which Dev requires
+	Files fs.FS
+}
+
+// New creates and configures the HTTP server for the site cfg describes
+func New(cfg config.Config, opts Options) (*Server, error) {
+	if opts.Dev && opts.Files != nil {
+		return nil, errors.New("dev mode watches files on disk; leave Options.Files unset")
+	}
+	templates, err := opts.open(cfg.Dirs.Templates)
+	if err != nil {
+		return nil, err
+	}
+	storeOpts, err := ContentOptions(cfg, opts)
+	if err != nil {
+		return nil, err
+	}
+	static, err := opts.open(cfg.Dirs.Static)
+	if err != nil {
+		return nil, err
+	}
+
 	// Initialize templates once at startup
-	if err := render.InitTemplates(); err != nil {
+	site := render.Site{Title: cfg.Site.Title, Author: cfg.Site.Author, Menu: cfg.Menu, Feeds: cfg.BaseURL != ""}
+	if err := render.InitTemplates(templates, site); err != nil {
+		return nil, err
+	}
+
+	// Load and render all content once at startup
+	if err := content.InitStore(storeOpts); err != nil {
 		return nil, err
 	}
 
 	s := &Server{
-		mux: http.NewServeMux(),
+		mux:    http.NewServeMux(),
+		cfg:    cfg,
+		opts:   opts,
+		static: static,
+	}
+	if opts.Dev {
+		s.reload = newLiveReload()
+		render.EnableLiveReload()
 	}
 	s.registerRoutes()
 	return s, nil
 }
 
+// open returns dir from Files, or from disk when there are no Files
+func (o Options) open(dir string) (fs.FS, error) {
+	if o.Files == nil {
+		return os.DirFS(dir), nil
+	}
+	name := path.Clean(filepath.ToSlash(dir))
+	if info, err := fs.Stat(o.Files, name); err != nil || !info.IsDir() {
+		return nil, fmt.Errorf("%s is not built into the binary; read it from disk instead", dir)
+	}
+	return fs.Sub(o.Files, name)
+}
+
+// ContentOptions returns the store options the server loads content with,
+// so other commands see the same entries it serves; git history and reloads
+// need the directory, which only exists for files on disk
+func ContentOptions(cfg config.Config, opts Options) (content.Options, error) {
+	contentFS, err := opts.open(cfg.Dirs.Content)
+	if err != nil {
+		return content.Options{}, err
+	}
+	storeOpts := content.Options{
+		FS:              contentFS,
+		Sections:        cfg.Sections,
+		Drafts:          opts.Drafts,
+		DefaultCategory: cfg.DefaultShelfCategory(),
+	}
+	if opts.Files == nil {
+		storeOpts.Dir = cfg.Dirs.Content
+	}
+	return storeOpts, nil
+}
+
 func (s *Server) registerRoutes() {
-	s.mux.HandleFunc("/", handlers.Home)
+	s.handleFunc("/", handlers.Home)
 
-	s.mux.HandleFunc("/articles", handlers.Articles)
-	s.mux.HandleFunc("/articles/", handlers.ArticleDetail)
-	s.mux.HandleFunc("/fragments", handlers.Fragments)
-	s.mux.HandleFunc("/fragments/", handlers.FragmentDetail)
-	s.mux.HandleFunc("/shelf", handlers.Shelf)
-	s.mux.HandleFunc("/shelf/", handlers.ShelfDetail)
-	s.mux.HandleFunc("/pixels", handlers.Pixels)
-	s.mux.HandleFunc("/about", handlers.About)
+	pageSize := s.cfg.PageSize
+	if s.cfg.Enabled("articles") {
+		s.handleFunc("/articles", handlers.Articles(pageSize))
+		s.handleFunc("/articles/", handlers.ArticleDetail)
+	}
+	if s.cfg.Enabled("fragments") {
+		s.handleFunc("/fragments", handlers.Fragments(pageSize))
+		s.handleFunc("/fragments/", handlers.FragmentDetail)
+	}
+	if s.cfg.Enabled("shelf") {
+		s.handleFunc("/shelf", handlers.Shelf(s.cfg.Shelf))
+		s.handleFunc("/shelf/", handlers.ShelfDetail)
+	}
+	if s.cfg.Enabled("pixels") {
+		s.handleFunc("/pixels", handlers.Pixels(pageSize))
+		s.handleFunc("/pixels/", handlers.PixelDetail)
+	}
+	if s.cfg.Enabled("about") {
+		s.handleFunc("/about", handlers.About)
+	}
+	// Disabled sections are not found rather than caught by the home page;
+	// they bypass handle so Export does not try to render them
+	for _, section := range config.Sections {
+		if !s.cfg.Enabled(section) {
+			s.mux.HandleFunc("/"+section, handlers.HandleNotFound)
+			s.mux.HandleFunc("/"+section+"/", handlers.HandleNotFound)
+		}
+	}
+	s.handleFunc("/series/", handlers.SeriesDetail)
+	s.handleFunc("/archive", handlers.Archive)
+	s.handleFunc("/archive/", handlers.ArchivePeriod)
+	s.handleFunc("/tags", handlers.Tags)
+	s.handleFunc("/tags/", handlers.TagDetail)
+	s.handleFunc("/search", handlers.Search)
+	s.handleFunc("/search.json", handlers.SearchJSON)
+	s.handleFunc("/preview/", handlers.Preview(s.opts.PreviewSecret))
+
+	// feeds for all content, per section and per tag query
+	// (/tags/{query}/feed.xml), and the sitemap; their absolute links need
+	// the configured base URL, so without one they are not found
+	feeds := handlers.Feed(s.cfg.BaseURL, s.cfg.Site)
+	sitemap := handlers.Sitemap(s.cfg.BaseURL, s.cfg.Sections)
+	if s.cfg.BaseURL == "" {
+		log.Print("No base URL: serving without feeds or a sitemap; set base_url, JOURNAL_BASE_URL or -base-url")
+		feeds, sitemap = handlers.HandleNotFound, handlers.HandleNotFound
+	}
+	for _, file := range handlers.FeedFiles {
+		s.handleFunc("/"+file, feeds)
+		for _, section := range []string{"articles", "fragments"} {
+			if s.cfg.Enabled(section) {
+				s.handleFunc("/"+section+"/"+file, feeds)
+			}
+		}
+		s.handleFunc("/tags/{query}/"+file, feeds)
+	}
+	s.handleFunc("/sitemap.xml", sitemap)
+	s.handleFunc("/sitemaps/", sitemap)
+	s.handleFunc("/robots.txt", handlers.Robots(s.cfg.BaseURL, s.opts.Robots))
+
+	if s.opts.Dev {
+		s.handle("/__livereload", s.reload)
+	}
 
 	// serve the static files
-	fileServer := http.FileServer(http.Dir("static"))
-	s.mux.Handle("/static/", http.StripPrefix("/static/", fileServer))
+	fileServer := http.FileServer(http.FS(s.static))
+	s.handle("/static/", http.StripPrefix("/static/", fileServer))
+}
+
+// handle registers h for pattern and records the pattern
+func (s *Server) handle(pattern string, h http.Handler) {
+	s.mux.Handle(pattern, h)
+	s.routes = append(s.routes, pattern)
 }
 
-// Start runs the HTTP server with middleware
-func (s *Server) Start(port string) error {
+func (s *Server) handleFunc(pattern string, h http.HandlerFunc) {
+	s.handle(pattern, h)
+}
+
+// Start serves on the configured address until ctx is done, then stops
+// accepting connections and gives open requests the shutdown timeout to
+// finish. It returns nil after a clean shutdown.
+func (s *Server) Start(ctx context.Context) error {
 	// Apply middleware: recovery first (outermost), then logging
 	handler := middleware.Recovery(middleware.Logging(s.mux))
 
-	log.Printf("Server starting on %s", port)
-	return http.ListenAndServe(port, handler)
+	ln, err := listen(s.cfg.Listen)
+	if err != nil {
+		return err
+	}
+
+	limits := s.cfg.HTTP
+	srv := &http.Server{
+		Handler:           handler,
+		ReadHeaderTimeout: limits.ReadHeaderTimeout,
+		ReadTimeout:       limits.ReadTimeout,
+		WriteTimeout:      limits.WriteTimeout,
+		IdleTimeout:       limits.IdleTimeout,
+		MaxHeaderBytes:    limits.MaxHeaderBytes,
+	}
+
+	if s.opts.Dev {
+		go s.watch(ctx)
+		// Shutdown waits for handlers, and the reload streams never end on their own
+		srv.RegisterOnShutdown(s.reload.close)
+	}
+
+	log.Printf("Server starting on %s", ln.Addr())
+	served := make(chan error, 1)
+	go func() { served <- srv.Serve(ln) }()
+
+	select {
+	case err := <-served:
+		return err
+	case <-ctx.Done():
+	}
+
+	log.Printf("Shutting down; waiting up to %s for open requests", limits.ShutdownTimeout)
+	drain := context.Background()
+	if limits.ShutdownTimeout > 0 {
+		var cancel context.CancelFunc
+		drain, cancel = context.WithTimeout(drain, limits.ShutdownTimeout)
+		defer cancel()
+	}
+	if err := srv.Shutdown(drain); err != nil {
+		srv.Close()
+		return fmt.Errorf("shutdown: %w", err)
+	}
+	return nil
 }
 
 /** What & Why's:
@@ -0,0 +1,105 @@
+package sitemap
+
+import (
+	"encoding/xml"
+	"io"
+	"time"
+)
+
+// MaxURLs is the most URLs a single sitemap file may list
+const MaxURLs = 50000
+
+// URL is one page in the sitemap, with any images shown on it
+type URL struct {
+	Loc     string
+	LastMod time.Time
+	Images  []string
+}
+
+// Index points at one sitemap file of a split sitemap
+type Index struct {
+	Loc     string
+	LastMod time.Time
+}
+
+// Write writes urls as a <urlset> sitemap with image extensions
+func Write(w io.Writer, urls []URL) error {
+	type image struct {
+		Loc string `xml:"image:loc"`
+	}
+	type url struct {
+		Loc     string  `xml:"loc"`
+		LastMod string  `xml:"lastmod,omitempty"`
+		Images  []image `xml:"image:image"`
+	}
+	type urlset struct {
+		XMLName xml.Name `xml:"urlset"`
+		NS      string   `xml:"xmlns,attr"`
+		ImageNS string   `xml:"xmlns:image,attr"`
+		URLs    []url    `xml:"url"`
+	}
+
+	doc := urlset{
+		NS:      "http://www.sitemaps.org/schemas/sitemap/0.9",
+		ImageNS: "http://www.google.com/schemas/sitemap-image/1.1",
+	}
+	for _, u := range urls {
+		entry := url{Loc: u.Loc, LastMod: formatDate(u.LastMod)}
+		for _, img := range u.Images {
+			entry.Images = append(entry.Images, image{Loc: img})
+		}
+		doc.URLs = append(doc.URLs, entry)
+	}
+
+	return writeXML(w, doc)
+}
+
+// WriteIndex writes a <sitemapindex> referencing each part of a split sitemap
+func WriteIndex(w io.Writer, parts []Index) error {
+	type sitemap struct {
+		Loc     string `xml:"loc"`
+		LastMod string `xml:"lastmod,omitempty"`
+	}
+	type sitemapIndex struct {
+		XMLName  xml.Name  `xml:"sitemapindex"`
+		NS       string    `xml:"xmlns,attr"`
+		Sitemaps []sitemap `xml:"sitemap"`
+	}
+
+	doc := sitemapIndex{NS: "http://www.sitemaps.org/schemas/sitemap/0.9"}
+	for _, p := range parts {
+		doc.Sitemaps = append(doc.Sitemaps, sitemap{Loc: p.Loc, LastMod: formatDate(p.LastMod)})
+	}
+
+	return writeXML(w, doc)
+}
+
+// Split chunks urls into sitemap-sized parts
+func Split(urls []URL) [][]URL {
+	var parts [][]URL
+	for len(urls) > MaxURLs {
+		parts = append(parts, urls[:MaxURLs])
+		urls = urls[MaxURLs:]
+	}
+	return append(parts, urls)
+}
+
+func formatDate(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format("2006-01-02")
+}
+
+func writeXML(w io.Writer, doc any) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
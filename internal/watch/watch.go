@@ -0,0 +1,88 @@
+package watch
+
+import (
+	"context"
+	"io/fs"
+	"path/filepath"
+	"time"
+)
+
+// fileState is what we compare between polls to detect a change
+type fileState struct {
+	modTime time.Time
+	size    int64
+}
+
+// Watcher polls directory trees and reports files that were created,
+// modified or removed since the previous poll.
+type Watcher struct {
+	roots    []string
+	interval time.Duration
+	files    map[string]fileState
+}
+
+// New creates a watcher over roots, taking an initial snapshot so that
+// only subsequent changes are reported
+func New(interval time.Duration, roots ...string) *Watcher {
+	w := &Watcher{
+		roots:    roots,
+		interval: interval,
+	}
+	w.files = w.scan()
+	return w
+}
+
+// Run polls until ctx is cancelled, calling onChange once per changed file
+func (w *Watcher) Run(ctx context.Context, onChange func(path string)) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			for _, path := range w.poll() {
+				onChange(path)
+			}
+		}
+	}
+}
+
+// poll rescans the roots and returns the paths that differ from the last snapshot
+func (w *Watcher) poll() []string {
+	current := w.scan()
+
+	var changed []string
+	for path, state := range current {
+		if prev, ok := w.files[path]; !ok || prev != state {
+			changed = append(changed, path)
+		}
+	}
+	for path := range w.files {
+		if _, ok := current[path]; !ok {
+			changed = append(changed, path)
+		}
+	}
+
+	w.files = current
+	return changed
+}
+
+func (w *Watcher) scan() map[string]fileState {
+	files := make(map[string]fileState)
+	for _, root := range w.roots {
+		filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() {
+				return nil
+			}
+			info, err := d.Info()
+			if err != nil {
+				return nil
+			}
+			files[path] = fileState{modTime: info.ModTime(), size: info.Size()}
+			return nil
+		})
+	}
+	return files
+}
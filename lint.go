@@ -0,0 +1,53 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"journal/internal/config"
+	"journal/internal/content"
+)
+
+// runLint checks every content file and exits non-zero when it finds errors,
+// or warnings too with -strict, so it can gate CI:
+// journal lint [-strict]
+func runLint(args []string) {
+	fs := flag.NewFlagSet("lint", flag.ExitOnError)
+	settings := config.BindFlags(fs)
+	strict := fs.Bool("strict", false, "fail on warnings as well as errors")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: journal lint [flags]")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	cfg, err := settings.Load()
+	if err != nil {
+		log.Fatalf("Error loading configuration: %v", err)
+	}
+
+	problems := content.Lint(content.LintOptions{
+		Dir:          cfg.Dirs.Content,
+		Sections:     cfg.Sections,
+		Categories:   cfg.ShelfKeys(),
+		StaticDir:    cfg.Dirs.Static,
+		TemplatesDir: cfg.Dirs.Templates,
+	})
+
+	var errors, warnings int
+	for _, p := range problems {
+		fmt.Println(p)
+		if p.Severity == content.Error {
+			errors++
+		} else {
+			warnings++
+		}
+	}
+	fmt.Fprintf(os.Stderr, "%d errors, %d warnings\n", errors, warnings)
+
+	if errors > 0 || *strict && warnings > 0 {
+		os.Exit(1)
+	}
+}
@@ -1,17 +1,78 @@
 package main
 
 import (
+	"context"
+	"flag"
 	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"journal/internal/config"
 	"journal/internal/server"
 )
 
 func main() {
-	s, err := server.New()
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "preview":
+			runPreview(os.Args[2:])
+			return
+		case "build":
+			runBuild(os.Args[2:])
+			return
+		case "new":
+			runNew(os.Args[2:])
+			return
+		case "lint":
+			runLint(os.Args[2:])
+			return
+		}
+	}
+
+	settings := config.BindFlags(flag.CommandLine)
+	dev := flag.Bool("dev", false, "reload content and templates when files change")
+	drafts := flag.Bool("drafts", false, "show drafts and future-dated entries")
+	live := flag.Bool("live", false, "read templates, content and static files from disk instead of the binary (implied by -dev)")
+	robotsFile := flag.String("robots", "", "file to serve as robots.txt (default: allow all but previews)")
+	flag.Parse()
+
+	cfg, err := settings.Load()
+	if err != nil {
+		log.Fatalf("Error loading configuration: %v", err)
+	}
+
+	var robots string
+	if *robotsFile != "" {
+		data, err := os.ReadFile(*robotsFile)
+		if err != nil {
+			log.Fatalf("Error reading robots file: %v", err)
+		}
+		robots = string(data)
+	}
+
+	opts := server.Options{
+		Dev:           *dev,
+		Drafts:        *drafts,
+		PreviewSecret: []byte(os.Getenv(previewSecretEnv)),
+		Robots:        robots,
+	}
+	if !*live && !*dev {
+		opts.Files = files
+	}
+	s, err := server.New(cfg, opts)
 	if err != nil {
 		log.Fatalf("Error initializing server: %v", err)
 	}
 
-	if err := s.Start(":8080"); err != nil {
+	// Stop on SIGINT or SIGTERM; a second signal kills the server outright
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+
+	if err := s.Start(ctx); err != nil {
 		log.Fatalf("Error starting server: %v", err)
 	}
 }
\ No newline at end of file
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+
+	"journal/internal/config"
+	"journal/internal/content"
+)
+
+// runNew scaffolds a draft entry with its frontmatter filled in:
+// journal new [-edit] [-category books] [-author NAME] [-image URL] <article|fragment|shelf|pixel> "Title"
+func runNew(args []string) {
+	fs := flag.NewFlagSet("new", flag.ExitOnError)
+	settings := config.BindFlags(fs)
+	edit := fs.Bool("edit", false, "open the new file in $EDITOR")
+	category := fs.String("category", "", "shelf category (default: the first configured)")
+	author := fs.String("author", "", "author of a shelf item")
+	image := fs.String("image", "", "image of a pixel (required for pixels)")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: journal new [flags] <%s> \"Title\"\n", strings.Join(content.ScaffoldSections, "|"))
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if fs.NArg() != 2 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	section, title := fs.Arg(0), fs.Arg(1)
+
+	cfg, err := settings.Load()
+	if err != nil {
+		log.Fatalf("Error loading configuration: %v", err)
+	}
+	if *category == "" {
+		*category = cfg.DefaultShelfCategory()
+	}
+	if section == "shelf" && !slices.Contains(cfg.ShelfKeys(), *category) {
+		log.Fatalf("Unknown shelf category %q: want %s", *category, strings.Join(cfg.ShelfKeys(), ", "))
+	}
+
+	file, err := content.Scaffold(section, title, content.ScaffoldOptions{
+		Dir:      cfg.Dirs.Content,
+		Category: *category,
+		Author:   *author,
+		Image:    *image,
+	})
+	if err != nil {
+		log.Fatalf("Error creating %s: %v", section, err)
+	}
+	fmt.Println(file)
+
+	if !*edit {
+		return
+	}
+	editor := strings.Fields(os.Getenv("EDITOR"))
+	if len(editor) == 0 {
+		log.Fatal("EDITOR is not set")
+	}
+	cmd := exec.Command(editor[0], append(editor[1:], file)...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := cmd.Run(); err != nil {
+		log.Fatalf("Error running %s: %v", editor[0], err)
+	}
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"net"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"journal/internal/config"
+	"journal/internal/content"
+	"journal/internal/preview"
+	"journal/internal/server"
+)
+
+// previewSecretEnv holds the key shared by the server and the preview command
+const previewSecretEnv = "JOURNAL_PREVIEW_SECRET"
+
+// runPreview mints a signed link to an unpublished entry:
+// journal preview [-ttl 168h] [-live] [-base-url URL] <articles|fragments|shelf> <slug>
+// The link points at the configured base URL, or the local server without one.
+func runPreview(args []string) {
+	fs := flag.NewFlagSet("preview", flag.ExitOnError)
+	settings := config.BindFlags(fs)
+	ttl := fs.Duration("ttl", 7*24*time.Hour, "how long the link stays valid")
+	live := fs.Bool("live", false, "read content from disk instead of the binary, as the server's -live does")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: journal preview [flags] <articles|fragments|shelf> <slug>")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if fs.NArg() != 2 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	section, slug := fs.Arg(0), fs.Arg(1)
+
+	secret := os.Getenv(previewSecretEnv)
+	if secret == "" {
+		log.Fatalf("%s is not set", previewSecretEnv)
+	}
+
+	cfg, err := settings.Load()
+	if err != nil {
+		log.Fatalf("Error loading configuration: %v", err)
+	}
+	baseURL := cfg.BaseURL
+	if baseURL == "" {
+		baseURL = "http://localhost"
+		if _, port, err := net.SplitHostPort(cfg.Listen); err == nil {
+			baseURL += ":" + port
+		}
+	}
+
+	// look the entry up where the server will, so the link does not point
+	// at a draft that exists only on disk while the binary serves its own
+	opts := server.Options{Drafts: true}
+	if !*live {
+		opts.Files = files
+	}
+	storeOpts, err := server.ContentOptions(cfg, opts)
+	if err != nil {
+		log.Fatalf("Error loading content: %v", err)
+	}
+	store, err := content.NewStore(storeOpts)
+	if err != nil {
+		log.Fatalf("Error loading content: %v", err)
+	}
+
+	var found bool
+	switch section {
+	case "articles":
+		_, found = store.DraftArticle(slug)
+	case "fragments":
+		_, found = store.DraftFragment(slug)
+	case "shelf":
+		_, found = store.DraftShelfItem(slug)
+	default:
+		log.Fatalf("Unknown section %q: want articles, fragments or shelf", section)
+	}
+	if !found {
+		log.Fatalf("No %s entry with slug %q", section, slug)
+	}
+
+	expires := time.Now().Add(*ttl)
+	token := preview.Sign([]byte(secret), section, slug, expires)
+
+	fmt.Printf("%s/preview/%s/%s?token=%s\n",
+		strings.TrimRight(baseURL, "/"), section, slug, url.QueryEscape(token))
+	fmt.Fprintf(os.Stderr, "Valid until %s\n", expires.Format(time.RFC1123))
+}